@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
 	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/config"
+	"health-check-app-micro/internal/incidents"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/notifier"
 	"health-check-app-micro/internal/registry"
 	"health-check-app-micro/internal/store"
 	"health-check-app-micro/pkg/utils"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,20 +30,146 @@ func main() {
 
 	// Cargar variables de entorno desde .env
 	if err := godotenv.Load(); err != nil {
-		utils.LogInfo("⚠️ No se encontró el archivo .env, se usarán variables del entorno")
+		utils.LogWarn("⚠️ No se encontró el archivo .env, se usarán variables del entorno")
 	}
 
-	storage := store.NewStore()
-	
-	// Registrar servicios automáticamente
-	configPath := os.Getenv("SERVICES_CONFIG_PATH")
+	// Configuración de la aplicación: flags > variables de entorno > archivo
+	// (-config o APP_CONFIG) > valores por defecto
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		utils.LogError("❌ " + err.Error())
+		os.Exit(2)
+	}
+	level, _ := utils.ParseLevel(cfg.LogLevel)
+	utils.SetLevel(level)
+	models.DefaultFrequency = int(time.Duration(cfg.Checker.Frequency) / time.Second)
+	checker.DefaultConfig = checker.SchedulerConfig{
+		Workers: cfg.Checker.Workers,
+		Timeout: time.Duration(cfg.Checker.Timeout),
+	}
+	notifier.SMTP = notifier.SMTPConfig{
+		Host:     cfg.Notifier.SMTPHost,
+		Port:     cfg.Notifier.SMTPPort,
+		User:     cfg.Notifier.SMTPUser,
+		Password: cfg.Notifier.SMTPPassword,
+		From:     cfg.Notifier.SMTPFrom,
+	}
+
+	storage, err := store.Open(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		utils.LogError("❌ Error abriendo el almacenamiento: " + err.Error())
+		os.Exit(1)
+	}
+
+	incidents.RepeatInterval = time.Duration(cfg.Incidents.RepeatInterval)
+
+	// En modo estricto una configuración inválida o ausente impide arrancar
+	// en lugar de usar lo que se pueda o los servicios por defecto
+	registry.StrictConfig = cfg.Services.Strict
+
+	// Registrar servicios automáticamente. El watcher se crea antes para no
+	// perder cambios del archivo hechos durante la carga inicial
+	configPath := cfg.Services.ConfigPath
+	watcher := registry.NewConfigWatcher(storage, configPath, time.Duration(cfg.Services.ReloadInterval))
 	if err := registry.AutoRegisterServices(storage, configPath); err != nil {
 		utils.LogError("❌ Error en registro automático: " + err.Error())
+		if registry.StrictConfig {
+			os.Exit(1)
+		}
 	}
-	
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go watcher.Run(watchCtx)
+
+	// SIGHUP fuerza la recarga de la configuración
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_ = watcher.Reload()
+		}
+	}()
+
 	go checker.StartHealthCheckLoop(storage) // inicia verificaciones periódicas individuales
 
-	router := api.SetupRouter(storage)
-	utils.LogInfo("🌐 Servidor iniciado en el puerto 8080")
-	router.Run(":8080")
+	opts := []api.Option{
+		api.WithRateLimit(cfg.RateLimit.RPS, cfg.RateLimit.Burst),
+		api.WithIPRateLimit(cfg.RateLimit.IPRPS, cfg.RateLimit.IPBurst),
+		api.WithMaxBodyBytes(cfg.Limits.MaxBodyBytes),
+		api.WithMaxServicesPerTenant(cfg.Limits.MaxServicesPerTenant),
+	}
+	if cfg.Auth.Enabled {
+		authCfg := auth.Config{
+			PublicRead:   cfg.Auth.PublicRead,
+			BootstrapKey: cfg.Auth.AdminAPIKey,
+		}
+		if cfg.Auth.JWT.Enabled() {
+			validator, err := newJWTValidator(cfg.Auth.JWT)
+			if err != nil {
+				utils.LogError("❌ Configuración JWT inválida: " + err.Error())
+				os.Exit(1)
+			}
+			authCfg.JWT = validator
+			utils.LogInfo("🔐 Validación de JWT habilitada")
+		}
+		opts = append(opts, api.WithAuth(auth.New(storage, authCfg)))
+		utils.LogInfo("🔐 Autenticación por API key habilitada")
+	}
+
+	router := api.SetupRouter(storage, opts...)
+	// Las peticiones heredan baseCtx, que se cancela al apagar para cerrar
+	// los streams de /events en lugar de esperar a que el cliente corte
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        cfg.Listen,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	server.RegisterOnShutdown(cancelBase)
+	go func() {
+		utils.LogInfo("🌐 Servidor iniciado en " + cfg.Listen)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			utils.LogError("❌ Error en el servidor HTTP: " + err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	// Apagado ordenado: dejar de aceptar peticiones y guardar cambios pendientes
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	utils.LogInfo("🛑 Deteniendo servidor...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		utils.LogError("❌ Error deteniendo el servidor: " + err.Error())
+	}
+	if err := storage.Close(); err != nil {
+		utils.LogError("❌ Error cerrando el almacenamiento: " + err.Error())
+	}
+}
+
+// newJWTValidator arma el validador de JWT a partir de la configuración.
+func newJWTValidator(c config.JWTConfig) (*auth.JWTValidator, error) {
+	cfg := auth.JWTConfig{
+		JWKSURL:       c.JWKSURL,
+		JWKSCachePath: c.JWKSCachePath,
+		Secret:        c.Secret,
+		Issuer:        c.Issuer,
+		Audience:      c.Audience,
+		RolesClaim:    c.RolesClaim,
+		TenantClaim:   c.TenantClaim,
+	}
+	if c.RoleScopes != "" {
+		roles, err := auth.ParseRoleScopes(c.RoleScopes)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RoleScopes = roles
+	}
+	return auth.NewJWTValidator(cfg)
 }
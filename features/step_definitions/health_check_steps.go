@@ -96,7 +96,7 @@ func consultoElEstadoDeUnServicioEspecifico(ctx *godog.ScenarioContext) error {
 
 func consultoElEndpointDeHealthCheck(ctx *godog.ScenarioContext) error {
 	ctx.Step(`^consulto el endpoint de health check$`, func() error {
-		resp, err := http.Get(baseURL + "/health")
+		resp, err := http.Get(baseURL + "/livez")
 		if err != nil {
 			return err
 		}
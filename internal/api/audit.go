@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// recordAudit registra el cambio en la auditoría con el autor autenticado y
+// la IP de origen de la petición.
+func recordAudit(c *gin.Context, storage store.Storage, action, target string, before, after any) {
+	audit.Record(storage, models.AuditEntry{
+		Actor:    actor(c, anonymousActor),
+		SourceIP: c.ClientIP(),
+		Action:   action,
+		Target:   target,
+	}, before, after)
+}
+
+// AuditHandler lista la auditoría de cambios, más recientes primero.
+// Acepta ?actor=, ?action=, ?target= (p. ej. service:api-gateway), ?since=
+// (RFC3339) y ?limit= (por defecto 100, máximo 1000).
+func AuditHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var since time.Time
+		if v := c.Query("since"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "since inválido, use RFC3339"})
+				return
+			}
+			since = t
+		}
+		limit := defaultAuditLimit
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit inválido"})
+				return
+			}
+			limit = min(n, maxAuditLimit)
+		}
+		actorFilter, action, target := c.Query("actor"), c.Query("action"), c.Query("target")
+
+		list := []models.AuditEntry{}
+		for _, e := range storage.ListAudit() {
+			if e.Time.Before(since) {
+				// Vienen de la más reciente a la más antigua
+				break
+			}
+			if (actorFilter != "" && e.Actor != actorFilter) || (action != "" && e.Action != action) ||
+				(target != "" && e.Target != target) {
+				continue
+			}
+			list = append(list, e)
+			if len(list) == limit {
+				break
+			}
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
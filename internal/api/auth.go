@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/ratelimit"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalContextKey es donde requireScope deja al principal autenticado.
+const principalContextKey = "principal"
+
+// statusSummaryKey marca las peticiones a /status que solo pueden ver los
+// contadores.
+const statusSummaryKey = "statusSummary"
+
+// anonymousActor es el autor de las acciones hechas sin key ni nombre explícito.
+const anonymousActor = "anonymous"
+
+// requireScope exige una API key o JWT con el scope indicado, presentada
+// como "Authorization: Bearer <key>" o en el header X-API-Key. Sin autenticador
+// configurado no exige nada. Con lecturas públicas, las peticiones de scope
+// read sin key pasan igual. Las keys de un tenant solo sirven en sus rutas
+// /t/:tenant.
+func requireScope(a *auth.Authenticator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+		presented := presentedKey(c)
+		if presented == "" && scope == models.ScopeRead && a.PublicRead() {
+			c.Next()
+			return
+		}
+		principal, ok := a.Authenticate(presented)
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer realm="health-check"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Credenciales requeridas o inválidas"})
+			return
+		}
+		if !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Sin permiso " + scope})
+			return
+		}
+		if !principal.CanAccess(c.Param("tenant")) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Sin acceso a este tenant"})
+			return
+		}
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// statusAccess deja /status abierto para balanceadores, pero sin lecturas
+// públicas solo quien tiene scope read en el tenant ve los nombres de los
+// servicios; el resto recibe los contadores. Las credenciales presentadas
+// pasan por el límite por IP, como en requireScope.
+func statusAccess(a *auth.Authenticator, ipLimiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil || a.PublicRead() {
+			c.Next()
+			return
+		}
+		presented := presentedKey(c)
+		if presented == "" {
+			c.Set(statusSummaryKey, true)
+			c.Next()
+			return
+		}
+		if !allow(c, ipLimiter, c.ClientIP()) {
+			return
+		}
+		principal, ok := a.Authenticate(presented)
+		if !ok || !principal.HasScope(models.ScopeRead) || !principal.CanAccess(c.Param("tenant")) {
+			c.Set(statusSummaryKey, true)
+		}
+		c.Next()
+	}
+}
+
+func presentedKey(c *gin.Context) string {
+	if h := c.GetHeader("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// actor devuelve el nombre del principal autenticado, o fallback si la
+// petición no trae credenciales.
+func actor(c *gin.Context, fallback string) string {
+	if v, ok := c.Get(principalContextKey); ok {
+		return v.(*auth.Principal).Name
+	}
+	return fallback
+}
+
+type apiKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// apiKeyView es una API key tal como se muestra, sin su hash.
+type apiKeyView struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Prefix    string   `json:"prefix,omitempty"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"createdAt,omitempty"`
+	Source    string   `json:"source"`
+	Tenant    string   `json:"tenant,omitempty"`
+	// AllTenants indica una key que opera en todos los tenants
+	AllTenants bool `json:"allTenants,omitempty"`
+}
+
+func newAPIKeyView(k models.APIKey) apiKeyView {
+	v := apiKeyView{ID: k.ID, Name: k.Name, Prefix: k.Prefix, Scopes: k.Scopes, Source: k.Source, Tenant: k.Tenant, AllTenants: k.AllTenants}
+	if !k.CreatedAt.IsZero() {
+		v.CreatedAt = k.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return v
+}
+
+// APIKeysListHandler lista las API keys sin exponer sus hashes.
+func APIKeysListHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list := []apiKeyView{}
+		for _, k := range storage.ListAPIKeys() {
+			list = append(list, newAPIKeyView(k))
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// APIKeyCreateHandler crea una API key. La respuesta incluye la key en
+// claro, que no se vuelve a mostrar.
+func APIKeyCreateHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req apiKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			rejectBody(c, err, "JSON inválido")
+			return
+		}
+		if req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El nombre es requerido"})
+			return
+		}
+		secret, key, err := auth.Create(storage, req.Name, req.Scopes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		key.Tenant = models.NormalizeTenant(c.Param("tenant"))
+		recordAudit(c, storage, models.AuditAPIKeyCreate, audit.Target(audit.TargetAPIKey, key.ID), nil, newAPIKeyView(key))
+		c.JSON(http.StatusCreated, gin.H{"key": secret, "apiKey": newAPIKeyView(key)})
+	}
+}
+
+// APIKeyDeleteHandler revoca una API key creada vía API. Las del archivo de
+// configuración se quitan editando el archivo.
+func APIKeyDeleteHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var before any
+		for _, k := range storage.ListAPIKeys() {
+			if k.ID != id {
+				continue
+			}
+			if k.Source == auth.SourceConfig {
+				c.JSON(http.StatusConflict, gin.H{"error": "La key está definida en el archivo de configuración"})
+				return
+			}
+			before = newAPIKeyView(k)
+		}
+		if !storage.DeleteAPIKey(id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key no encontrada"})
+			return
+		}
+		recordAudit(c, storage, models.AuditAPIKeyDelete, audit.Target(audit.TargetAPIKey, id), before, nil)
+		c.Status(http.StatusNoContent)
+	}
+}
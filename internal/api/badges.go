@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/badge"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tiempo que proxies y navegadores pueden cachear cada badge. El de estado
+// es corto para que se vea la caída; el de uptime cambia lentamente.
+const (
+	statusBadgeMaxAge = 30 * time.Second
+	uptimeBadgeMaxAge = 5 * time.Minute
+)
+
+// StatusBadgeHandler genera un badge SVG con el estado actual del servicio.
+// ?label= cambia el texto de la izquierda (por defecto el nombre).
+func StatusBadgeHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		label := c.DefaultQuery("label", name)
+		service := storage.Get(name)
+		if service == nil {
+			writeBadge(c, http.StatusNotFound, 0, label, "not found", badge.ColorGrey)
+			return
+		}
+		writeBadge(c, http.StatusOK, statusBadgeMaxAge, label, strings.ToLower(service.Status), badge.StatusColor(service.Status))
+	}
+}
+
+// UptimeBadgeHandler genera un badge SVG con el uptime del servicio en
+// ?window= (por defecto 24h). Si los datos no cubren toda la ventana, el
+// mensaje indica la que cubren, p. ej. "99.50% (8h)".
+func UptimeBadgeHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		window := c.DefaultQuery("window", defaultUptimeWindow)
+		label := c.DefaultQuery("label", "uptime "+window)
+		service := storage.Get(name)
+		if service == nil {
+			writeBadge(c, http.StatusNotFound, 0, label, "not found", badge.ColorGrey)
+			return
+		}
+		d, err := report.ParseWindow(window)
+		if err != nil {
+			writeBadge(c, http.StatusBadRequest, 0, "uptime", "invalid window", badge.ColorGrey)
+			return
+		}
+
+		now := time.Now()
+		u := report.WindowUptime(storage, name, window, d, now)
+		if u.UptimePercent == nil {
+			writeBadge(c, http.StatusOK, uptimeBadgeMaxAge, label, "n/a", badge.ColorGrey)
+			return
+		}
+		message := formatPercent(*u.UptimePercent)
+		if covered, full := u.Coverage(now, frequencyOf(service)); !full {
+			message += " (" + report.FormatWindow(covered) + ")"
+		}
+		writeBadge(c, http.StatusOK, uptimeBadgeMaxAge, label, message, badge.UptimeColor(*u.UptimePercent))
+	}
+}
+
+func writeBadge(c *gin.Context, code int, maxAge time.Duration, label, message, color string) {
+	if maxAge > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	c.Data(code, "image/svg+xml; charset=utf-8", badge.Render(label, message, color))
+}
+
+// frequencyOf devuelve cada cuánto se verifica el servicio.
+func frequencyOf(service *models.Microservice) time.Duration {
+	if service.Frequency <= 0 {
+		return time.Duration(models.DefaultFrequency) * time.Second
+	}
+	return time.Duration(service.Frequency) * time.Second
+}
+
+// formatPercent muestra dos decimales salvo que el uptime sea exacto.
+func formatPercent(p float64) string {
+	if p == 100 {
+		return "100%"
+	}
+	return fmt.Sprintf("%.2f%%", p)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"health-check-app-micro/internal/events"
+	"health-check-app-micro/internal/models"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval es cada cuánto se envía un comentario al cliente para
+// que proxies intermedios no cierren un stream sin eventos.
+const heartbeatInterval = 15 * time.Second
+
+// EventsHandler publica como Server-Sent Events los resultados de checks y
+// los cambios de estado. Acepta ?service= y ?tag= (repetibles) para filtrar,
+// y reanuda desde el header Last-Event-ID (o ?lastEventId=) con los eventos
+// que sigan en el buffer del broker.
+func EventsHandler(broker *events.Broker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lastID, err := lastEventID(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Last-Event-ID inválido"})
+			return
+		}
+
+		filter := events.Filter{
+			Tenant:   models.NormalizeTenant(c.Param("tenant")),
+			Services: c.QueryArray("service"),
+			Tags:     c.QueryArray("tag"),
+		}
+		sub, backlog := broker.Subscribe(filter, lastID)
+		defer broker.Unsubscribe(sub)
+
+		h := c.Writer.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		for _, e := range backlog {
+			if writeEvent(c, e) != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case e, ok := <-sub.C:
+				if !ok {
+					// Desconectado por lento: el cliente reanuda con Last-Event-ID
+					return
+				}
+				if writeEvent(c, e) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, e events.Event) error {
+	return sse.Encode(c.Writer, sse.Event{
+		Id:    strconv.FormatUint(e.ID, 10),
+		Event: e.Type,
+		Data:  e,
+	})
+}
+
+func lastEventID(c *gin.Context) (uint64, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("lastEventId")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
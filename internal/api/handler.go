@@ -2,22 +2,34 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"health-check-app-micro/internal/audit"
 	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/graph"
 	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/report"
 	"health-check-app-micro/internal/store"
 	"health-check-app-micro/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterHandler(storage *store.Store) gin.HandlerFunc {
+func RegisterHandler(storage store.Storage) gin.HandlerFunc {
+	return registerHandler(storage, 0)
+}
+
+// registerHandler es RegisterHandler con un máximo de servicios en el
+// tenant; con 0 no hay límite. Actualizar un servicio existente siempre se
+// permite.
+func registerHandler(storage store.Storage, maxServices int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var service models.Microservice
 		if err := c.ShouldBindJSON(&service); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+			rejectBody(c, err, "JSON inválido")
 			return
 		}
 		
@@ -26,6 +38,10 @@ func RegisterHandler(storage *store.Store) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "El nombre es requerido"})
 			return
 		}
+		if !models.ValidServiceName(service.Name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El nombre no puede contener /"})
+			return
+		}
 		if service.Endpoint == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "El endpoint es requerido"})
 			return
@@ -35,12 +51,51 @@ func RegisterHandler(storage *store.Store) gin.HandlerFunc {
 			return
 		}
 		if service.Frequency < 10 {
-			service.Frequency = 30 // mínimo 10 segundos, default 30
+			service.Frequency = models.DefaultFrequency // mínimo 10 segundos
+		}
+		for _, dep := range service.DependsOn {
+			if !models.ValidServiceName(dep) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Dependencia inválida: " + dep})
+				return
+			}
+		}
+		if len(service.DependsOn) > 0 {
+			deps := graph.DependencyMap(storage.GetAll())
+			deps[service.Name] = service.DependsOn
+			if cycle := graph.FindCycle(deps); cycle != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": graph.CycleError(cycle).Error()})
+				return
+			}
 		}
 		
 		service.Status = "UNKNOWN"
 		service.LastCheck = time.Now().Format(time.RFC3339)
-		storage.RegisterService(service)
+		service.Source = registry.SourceAPI
+		before := storage.Get(service.Name)
+		if before != nil {
+			// La pausa solo cambia con /pause y /resume
+			service.Paused = before.Paused
+		}
+		// El conteo y el alta son atómicos en el store para que dos altas
+		// simultáneas no superen el máximo
+		ok, err := storage.RegisterServiceLimited(service, maxServices)
+		if err != nil {
+			utils.LogError("❌ Error registrando servicio " + service.Name + ": " + err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo guardar el servicio"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "Se alcanzó el máximo de " + strconv.Itoa(maxServices) + " servicios"})
+			return
+		}
+		if registered := storage.Get(service.Name); registered != nil {
+			service = *registered // con el tenant de la ruta
+		}
+		action := models.AuditServiceRegister
+		if before != nil {
+			action = models.AuditServiceUpdate
+		}
+		recordAudit(c, storage, action, audit.Target(audit.TargetService, service.Name), audit.Service(before), audit.Service(&service))
 		
 		// Iniciar monitoreo del servicio
 		checker.RegisterNewService(storage, &service)
@@ -54,13 +109,65 @@ func RegisterHandler(storage *store.Store) gin.HandlerFunc {
 	}
 }
 
-func HealthAllHandler(storage *store.Store) gin.HandlerFunc {
+// HealthAllHandler devuelve el mapa de servicios. Acepta ?tag= (repetible,
+// deben cumplirse todos) y ?status= para filtrarlo.
+func HealthAllHandler(storage store.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusOK, storage.GetAll())
+		c.JSON(http.StatusOK, filterServices(storage.GetAll(), c.QueryArray("tag"), c.Query("status")))
 	}
 }
 
-func HealthOneHandler(storage *store.Store) gin.HandlerFunc {
+// GroupsHandler agrega el estado de los servicios por el valor de un tag
+// clave:valor, por ejemplo ?by=team. También acepta los filtros de /health.
+func GroupsHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Query("by")
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El parámetro by es requerido"})
+			return
+		}
+		services := filterServices(storage.GetAll(), c.QueryArray("tag"), c.Query("status"))
+		c.JSON(http.StatusOK, report.GroupBy(services, key))
+	}
+}
+
+// StatusHandler responde si la plataforma está sana, para balanceadores y
+// orquestadores: 200 con UP o DEGRADED, 503 con DOWN. Acepta ?tag= para
+// limitarlo, por ejemplo, a env:prod. Sin permiso de lectura (ver
+// statusAccess) solo devuelve los contadores, sin nombres de servicios.
+func StatusHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		platform := report.PlatformStatus(filterServices(storage.GetAll(), c.QueryArray("tag"), ""))
+		code := http.StatusOK
+		if platform.Status == report.StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		if c.GetBool(statusSummaryKey) {
+			c.JSON(code, platform.Summary())
+			return
+		}
+		c.JSON(code, platform)
+	}
+}
+
+func filterServices(all map[string]*models.Microservice, tags []string, status string) map[string]*models.Microservice {
+	if len(tags) == 0 && status == "" {
+		return all
+	}
+	filtered := make(map[string]*models.Microservice)
+	for name, s := range all {
+		if !s.HasTags(tags) {
+			continue
+		}
+		if status != "" && !strings.EqualFold(s.Status, status) {
+			continue
+		}
+		filtered[name] = s
+	}
+	return filtered
+}
+
+func HealthOneHandler(storage store.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		name := c.Param("name")
 		service := storage.Get(name)
@@ -71,3 +178,37 @@ func HealthOneHandler(storage *store.Store) gin.HandlerFunc {
 		c.JSON(http.StatusOK, service)
 	}
 }
+
+// SchedulerStatsHandler expone profundidad de cola y atraso del scheduler de checks.
+func SchedulerStatsHandler(scheduler *checker.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, scheduler.Stats())
+	}
+}
+
+// CheckOneHandler ejecuta de inmediato el check de un servicio y devuelve el resultado.
+func CheckOneHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, ok := checker.CheckNow(storage, c.Param("name"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Microservicio no encontrado"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// CheckAllHandler ejecuta de inmediato el check de todos los servicios registrados.
+func CheckAllHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, checker.CheckAll(storage))
+	}
+}
+
+// GraphHandler devuelve la topología de dependencias con el estado actual
+// de cada servicio.
+func GraphHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, graph.Build(storage.GetAll()))
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/incidents"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ackRequest struct {
+	By   string `json:"by"`
+	Note string `json:"note"`
+}
+
+type noteRequest struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// IncidentsListHandler lista los incidentes, filtrables por ?service= y ?status=open|resolved.
+func IncidentsListHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := c.Query("service")
+		status := c.Query("status")
+
+		list := []models.Incident{}
+		for _, incident := range storage.ListIncidents() {
+			if service != "" && incident.Service != service {
+				continue
+			}
+			if status != "" && incident.Status != status {
+				continue
+			}
+			list = append(list, incident)
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func IncidentOneHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		incident := storage.GetIncident(c.Param("id"))
+		if incident == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incidente no encontrado"})
+			return
+		}
+		c.JSON(http.StatusOK, incident)
+	}
+}
+
+// IncidentAckHandler reconoce un incidente abierto y detiene sus alertas repetidas.
+func IncidentAckHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ackRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				rejectBody(c, err, "JSON inválido")
+				return
+			}
+		}
+		if req.By == "" {
+			req.By = actor(c, anonymousActor)
+		}
+
+		before := storage.GetIncident(c.Param("id"))
+		incident, err := incidents.Acknowledge(storage, c.Param("id"), req.By, req.Note)
+		if err != nil {
+			c.JSON(incidentErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		after := ackState(incident)
+		if req.Note != "" {
+			after["note"] = req.Note
+		}
+		recordAudit(c, storage, models.AuditIncidentAck, audit.Target(audit.TargetIncident, incident.ID), ackState(before), after)
+		c.JSON(http.StatusOK, incident)
+	}
+}
+
+func IncidentNoteHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req noteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			rejectBody(c, err, "JSON inválido")
+			return
+		}
+		if req.Text == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El texto de la nota es requerido"})
+			return
+		}
+		if req.Author == "" {
+			req.Author = actor(c, anonymousActor)
+		}
+
+		incident, err := incidents.AddNote(storage, c.Param("id"), req.Author, req.Text)
+		if err != nil {
+			c.JSON(incidentErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, incident)
+	}
+}
+
+// ackState es el estado de reconocimiento del incidente que se audita.
+func ackState(i *models.Incident) gin.H {
+	if i == nil {
+		return gin.H{}
+	}
+	return gin.H{"acknowledged": i.Acknowledged, "acknowledgedBy": i.AcknowledgedBy}
+}
+
+func incidentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, incidents.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, incidents.ErrResolved):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
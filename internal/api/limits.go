@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes es el tamaño máximo por defecto del cuerpo de una petición.
+const DefaultMaxBodyBytes = 1 << 20
+
+// WithRateLimit limita cada cliente (API key o JWT autenticado, o IP si no
+// presenta credenciales) a rps peticiones por segundo con ráfagas de burst.
+// Las probes y /status no se limitan.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(cfg *routerConfig) {
+		if rps > 0 {
+			cfg.limiter = ratelimit.New(rps, burst)
+		}
+	}
+}
+
+// WithIPRateLimit limita cada IP de origen a rps peticiones por segundo con
+// ráfagas de burst antes de autenticar, para frenar también los intentos
+// con credenciales inválidas. Debería ser más holgado que WithRateLimit:
+// varios clientes pueden compartir una IP.
+func WithIPRateLimit(rps float64, burst int) Option {
+	return func(cfg *routerConfig) {
+		if rps > 0 {
+			cfg.ipLimiter = ratelimit.New(rps, burst)
+		}
+	}
+}
+
+// WithMaxBodyBytes cambia el tamaño máximo del cuerpo de las peticiones
+// (DefaultMaxBodyBytes si no se indica).
+func WithMaxBodyBytes(n int64) Option {
+	return func(cfg *routerConfig) {
+		cfg.maxBodyBytes = n
+	}
+}
+
+// WithMaxServicesPerTenant limita cuántos servicios puede registrar cada
+// tenant vía API. Con 0 no hay límite.
+func WithMaxServicesPerTenant(n int) Option {
+	return func(cfg *routerConfig) {
+		cfg.maxServices = n
+	}
+}
+
+// protect arma la cadena de middlewares de un endpoint con scope: el límite
+// por IP va antes de requireScope para frenar también las credenciales
+// inválidas, y el límite por cliente después, para identificarlo por su
+// credencial.
+func (cfg routerConfig) protect(scope string) []gin.HandlerFunc {
+	return []gin.HandlerFunc{ipRateLimit(cfg.ipLimiter), requireScope(cfg.auth, scope), rateLimit(cfg.limiter)}
+}
+
+// rateLimit responde 429 con Retry-After cuando el cliente agotó su cuota.
+func rateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return throttle(limiter, clientKey)
+}
+
+// ipRateLimit responde 429 con Retry-After cuando la IP de origen agotó su
+// cuota.
+func ipRateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return throttle(limiter, func(c *gin.Context) string { return c.ClientIP() })
+}
+
+func throttle(limiter *ratelimit.Limiter, key func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allow(c, limiter, key(c)) {
+			c.Next()
+		}
+	}
+}
+
+// allow consume la cuota de key y, si se agotó, responde 429 con
+// Retry-After. Sin limiter siempre permite.
+func allow(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	if limiter == nil {
+		return true
+	}
+	ok, wait := limiter.Allow(key)
+	if !ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Demasiadas peticiones, intente más tarde"})
+	}
+	return ok
+}
+
+// clientKey identifica al cliente para el rate limit: el principal
+// autenticado o, sin credenciales, la IP de origen.
+func clientKey(c *gin.Context) string {
+	if v, ok := c.Get(principalContextKey); ok {
+		p := v.(*auth.Principal)
+		return p.Method + ":" + models.QualifiedName(p.Tenant, p.Name)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// limitBody rechaza con 413 los cuerpos declarados más grandes que max y
+// corta la lectura de los que lo superen sin declararlo.
+func limitBody(max int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > max {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cuerpo de la petición demasiado grande"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
+
+// rejectBody responde a un error leyendo el cuerpo: 413 si superó el máximo
+// de limitBody sin declararlo y 400 con msg en otro caso.
+func rejectBody(c *gin.Context, err error, msg string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cuerpo de la petición demasiado grande"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+}
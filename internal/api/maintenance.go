@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/maintenance"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultUptimeWindow = "24h"
+
+type maintenanceRequest struct {
+	Services        []string  `json:"services"`
+	Tags            []string  `json:"tags"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationMinutes int       `json:"durationMinutes"` // alternativa a end
+	Recurrence      string    `json:"recurrence"`
+	Reason          string    `json:"reason"`
+}
+
+// MaintenanceListHandler lista las ventanas de mantenimiento; con ?active=true
+// solo las vigentes en este momento.
+func MaintenanceListHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		onlyActive := c.Query("active") == "true"
+		now := time.Now()
+
+		list := []models.MaintenanceWindow{}
+		for _, w := range storage.ListMaintenance() {
+			if onlyActive && !w.ActiveAt(now) {
+				continue
+			}
+			list = append(list, w)
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// MaintenanceCreateHandler crea una ventana de mantenimiento. Sin start
+// comienza de inmediato; el fin puede indicarse con end o durationMinutes.
+func MaintenanceCreateHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req maintenanceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			rejectBody(c, err, "JSON inválido")
+			return
+		}
+		if req.Start.IsZero() {
+			req.Start = time.Now()
+		}
+		if req.End.IsZero() && req.DurationMinutes > 0 {
+			req.End = req.Start.Add(time.Duration(req.DurationMinutes) * time.Minute)
+		}
+
+		window := models.MaintenanceWindow{
+			ID:         maintenance.NewID(),
+			Services:   req.Services,
+			Tags:       req.Tags,
+			Start:      req.Start,
+			End:        req.End,
+			Recurrence: req.Recurrence,
+			Reason:     req.Reason,
+			Source:     maintenance.SourceAPI,
+		}
+		if err := maintenance.Validate(window); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		storage.SaveMaintenance(window)
+		recordAudit(c, storage, models.AuditMaintenanceCreate, audit.Target(audit.TargetMaintenance, window.ID), nil, window)
+		c.JSON(http.StatusCreated, window)
+	}
+}
+
+// MaintenanceDeleteHandler elimina una ventana, terminándola si estaba vigente.
+func MaintenanceDeleteHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var before any
+		for _, w := range storage.ListMaintenance() {
+			if w.ID == id {
+				before = w
+			}
+		}
+		if !storage.DeleteMaintenance(id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ventana de mantenimiento no encontrada"})
+			return
+		}
+		recordAudit(c, storage, models.AuditMaintenanceDelete, audit.Target(audit.TargetMaintenance, id), before, nil)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// UptimeAllHandler devuelve el uptime de todos los servicios en ?window= (por defecto 24h).
+func UptimeAllHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window, d, ok := uptimeWindow(c)
+		if !ok {
+			return
+		}
+		now := time.Now()
+		list := []report.Uptime{}
+		for name := range storage.GetAll() {
+			list = append(list, report.WindowUptime(storage, name, window, d, now))
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Service < list[j].Service })
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func UptimeOneHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if storage.Get(name) == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Microservicio no encontrado"})
+			return
+		}
+		window, d, ok := uptimeWindow(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, report.WindowUptime(storage, name, window, d, time.Now()))
+	}
+}
+
+func uptimeWindow(c *gin.Context) (string, time.Duration, bool) {
+	window := c.DefaultQuery("window", defaultUptimeWindow)
+	d, err := report.ParseWindow(window)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", 0, false
+	}
+	return window, d, true
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/notifier"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxCheckLag es el atraso máximo del scheduler con el que la app sigue
+// considerándose lista.
+var MaxCheckLag = time.Minute
+
+// probeCheck es el resultado de una de las verificaciones de /readyz.
+type probeCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// LivezHandler indica que el proceso está vivo y atendiendo peticiones. No
+// depende de nada externo para que Kubernetes no lo reinicie por una falla
+// que un reinicio no resuelve.
+func LivezHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// ReadyzHandler verifica el estado propio de la app: store escribible,
+// scheduler en marcha y al día, y cola de notificaciones sin trabarse.
+// Responde 503 si alguna verificación falla.
+func ReadyzHandler(storage store.Storage, scheduler *checker.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checks := map[string]probeCheck{}
+
+		if err := storage.Ping(); err != nil {
+			checks["store"] = probeCheck{Detail: err.Error()}
+		} else {
+			checks["store"] = probeCheck{OK: true}
+		}
+
+		stats := scheduler.Stats()
+		if stats.Running {
+			checks["scheduler"] = probeCheck{OK: true}
+		} else {
+			checks["scheduler"] = probeCheck{Detail: "el scheduler no está en marcha"}
+		}
+		lag := time.Duration(stats.LagSeconds * float64(time.Second))
+		if lag > MaxCheckLag {
+			checks["checks"] = probeCheck{Detail: fmt.Sprintf("checks atrasados %s (%d en cola)", lag.Round(time.Second), stats.QueueDepth)}
+		} else {
+			checks["checks"] = probeCheck{OK: true}
+		}
+
+		if q := notifier.Stats(); q.Stuck {
+			checks["notifier"] = probeCheck{Detail: fmt.Sprintf("envío en curso hace %.0fs, %d pendientes", q.SendingSeconds, q.Pending)}
+		} else {
+			checks["notifier"] = probeCheck{OK: true}
+		}
+
+		status, code := "ok", http.StatusOK
+		for _, check := range checks {
+			if !check.OK {
+				status, code = "fail", http.StatusServiceUnavailable
+				break
+			}
+		}
+		c.JSON(code, gin.H{"status": status, "checks": checks})
+	}
+}
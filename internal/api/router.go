@@ -1,17 +1,117 @@
 package api
 
 import (
+	"net/http"
+
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/events"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/ratelimit"
 	"health-check-app-micro/internal/store"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(storage *store.Store) *gin.Engine {
+// Option ajusta el router creado por SetupRouter.
+type Option func(*routerConfig)
+
+type routerConfig struct {
+	auth         *auth.Authenticator
+	limiter      *ratelimit.Limiter
+	ipLimiter    *ratelimit.Limiter
+	maxBodyBytes int64
+	maxServices  int
+}
+
+// WithAuth exige API keys según el scope de cada endpoint y habilita la
+// administración de keys en /admin/apikeys.
+func WithAuth(a *auth.Authenticator) Option {
+	return func(cfg *routerConfig) {
+		cfg.auth = a
+	}
+}
+
+func SetupRouter(storage store.Storage, opts ...Option) *gin.Engine {
+	cfg := routerConfig{maxBodyBytes: DefaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	r := gin.Default()
+	r.Use(limitBody(cfg.maxBodyBytes))
 
-	r.POST("/register", RegisterHandler(storage))
-	r.GET("/health", HealthAllHandler(storage))
-	r.GET("/health/:name", HealthOneHandler(storage))
+	// Probes de Kubernetes y balanceadores: siempre públicos
+	r.GET("/livez", LivezHandler())
+	r.GET("/readyz", ReadyzHandler(storage, checker.DefaultScheduler()))
+	r.GET("/scheduler", append(cfg.protect(models.ScopeRead), SchedulerStatsHandler(checker.DefaultScheduler()))...)
+
+	// Cada equipo ve y modifica solo lo suyo: las rutas sin prefijo son del
+	// tenant por defecto y /t/:tenant/... las de cada tenant
+	tenantRoutes(r.Group("/"), storage, cfg)
+	tenantRoutes(r.Group("/t/:tenant", validTenant()), storage, cfg)
 
 	return r
 }
+
+// tenantRoutes registra en g los endpoints que operan sobre los datos de un
+// tenant. Cada petición usa la vista del store del tenant de su ruta.
+func tenantRoutes(g *gin.RouterGroup, storage store.Storage, cfg routerConfig) {
+	scoped := func(build func(store.Storage) gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			build(store.ForTenant(storage, c.Param("tenant")))(c)
+		}
+	}
+
+	g.GET("/status", statusAccess(cfg.auth, cfg.ipLimiter), scoped(StatusHandler))
+	g.HEAD("/status", statusAccess(cfg.auth, cfg.ipLimiter), scoped(StatusHandler))
+
+	read := g.Group("/", cfg.protect(models.ScopeRead)...)
+	read.GET("/health", scoped(HealthAllHandler))
+	read.GET("/health/:name", scoped(HealthOneHandler))
+	read.GET("/groups", scoped(GroupsHandler))
+	read.GET("/status-page", scoped(StatusPageHandler))
+	read.GET("/incidents", scoped(IncidentsListHandler))
+	read.GET("/incidents/:id", scoped(IncidentOneHandler))
+	read.GET("/maintenance", scoped(MaintenanceListHandler))
+	read.GET("/uptime", scoped(UptimeAllHandler))
+	read.GET("/uptime/:name", scoped(UptimeOneHandler))
+	read.GET("/badge/:name/status.svg", scoped(StatusBadgeHandler))
+	read.GET("/badge/:name/uptime.svg", scoped(UptimeBadgeHandler))
+	read.GET("/graph", scoped(GraphHandler))
+	read.GET("/events", EventsHandler(events.Default()))
+
+	write := g.Group("/", cfg.protect(models.ScopeRegister)...)
+	write.POST("/register", scoped(func(s store.Storage) gin.HandlerFunc { return registerHandler(s, cfg.maxServices) }))
+	write.PUT("/services", scoped(func(s store.Storage) gin.HandlerFunc { return servicesSyncHandler(s, cfg.maxServices) }))
+	write.POST("/services/:name/check", scoped(CheckOneHandler))
+	write.POST("/services/:name/pause", scoped(ServicePauseHandler))
+	write.POST("/services/:name/resume", scoped(ServiceResumeHandler))
+	write.DELETE("/services/:name", scoped(ServiceDeleteHandler))
+	write.POST("/check", scoped(CheckAllHandler))
+	write.POST("/incidents/:id/ack", scoped(IncidentAckHandler))
+	write.POST("/incidents/:id/notes", scoped(IncidentNoteHandler))
+	write.POST("/maintenance", scoped(MaintenanceCreateHandler))
+	write.DELETE("/maintenance/:id", scoped(MaintenanceDeleteHandler))
+
+	// La auditoría incluye IPs y configuración de todo el tenant: solo admin
+	g.GET("/audit", append(cfg.protect(models.ScopeAdmin), scoped(AuditHandler))...)
+
+	if cfg.auth != nil {
+		admin := g.Group("/admin", cfg.protect(models.ScopeAdmin)...)
+		admin.GET("/apikeys", scoped(APIKeysListHandler))
+		admin.POST("/apikeys", scoped(APIKeyCreateHandler))
+		admin.DELETE("/apikeys/:id", scoped(APIKeyDeleteHandler))
+	}
+}
+
+// validTenant rechaza las rutas /t/:tenant con un nombre de tenant inválido.
+func validTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.ValidTenant(c.Param("tenant")) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Tenant inválido"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceDeleteHandler deja de monitorear un servicio y lo elimina junto con
+// su historial. Sus incidentes se conservan.
+func ServiceDeleteHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		before := storage.Get(name)
+		if before == nil || !storage.DeleteService(name) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Microservicio no encontrado"})
+			return
+		}
+		recordAudit(c, storage, models.AuditServiceDelete, audit.Target(audit.TargetService, name), audit.Service(before), nil)
+		utils.LogInfo("🗑️ Servicio eliminado: " + before.QualifiedName())
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ServicePauseHandler pausa los checks programados de un servicio.
+func ServicePauseHandler(storage store.Storage) gin.HandlerFunc {
+	return setPausedHandler(storage, true)
+}
+
+// ServiceResumeHandler reanuda los checks de un servicio pausado y lo
+// verifica de inmediato.
+func ServiceResumeHandler(storage store.Storage) gin.HandlerFunc {
+	return setPausedHandler(storage, false)
+}
+
+func setPausedHandler(storage store.Storage, paused bool) gin.HandlerFunc {
+	action := models.AuditServiceResume
+	if paused {
+		action = models.AuditServicePause
+	}
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		before := storage.Get(name)
+		if before == nil || !storage.SetPaused(name, paused) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Microservicio no encontrado"})
+			return
+		}
+		after := *before
+		after.Paused = paused
+		if before.Paused != paused {
+			recordAudit(c, storage, action, audit.Target(audit.TargetService, name), audit.Service(before), audit.Service(&after))
+			if !paused {
+				checker.RegisterNewService(storage, &after)
+			}
+		}
+		c.JSON(http.StatusOK, after)
+	}
+}
+
+// ServicesSyncHandler reemplaza los servicios del tenant por la lista del
+// cuerpo, con la misma forma que services-config.json. Crea los nuevos y
+// actualiza los que cambiaron; con ?prune=true también elimina los que no
+// están, y con ?dryRun=true solo devuelve los cambios que haría. Si algún
+// servicio es inválido no aplica nada.
+func ServicesSyncHandler(storage store.Storage) gin.HandlerFunc {
+	return servicesSyncHandler(storage, 0)
+}
+
+func servicesSyncHandler(storage store.Storage, maxServices int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prune, err1 := strconv.ParseBool(c.DefaultQuery("prune", "false"))
+		dryRun, err2 := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+		if err1 != nil || err2 != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prune y dryRun deben ser true o false"})
+			return
+		}
+		data, err := c.GetRawData()
+		if err != nil {
+			rejectBody(c, err, "No se pudo leer el cuerpo")
+			return
+		}
+		cfg, err := registry.ParseConfig(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+			return
+		}
+		if len(cfg.Maintenance) > 0 || len(cfg.NotificationRoutes) > 0 || len(cfg.APIKeys) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Solo se sincronizan servicios"})
+			return
+		}
+		tenant := models.NormalizeTenant(c.Param("tenant"))
+		for _, s := range cfg.Services {
+			if models.NormalizeTenant(s.Tenant) != tenant {
+				c.JSON(http.StatusBadRequest, gin.H{"error": s.Name + ": el tenant no coincide con el de la ruta"})
+				return
+			}
+		}
+
+		result, err := registry.Reconcile(storage, cfg.Services, registry.SyncOptions{
+			Prune:       prune,
+			DryRun:      dryRun,
+			Source:      registry.SourceAPI,
+			MaxServices: maxServices,
+			Actor:       actor(c, anonymousActor),
+			SourceIP:    c.ClientIP(),
+		})
+		var invalid *registry.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Configuración de servicios inválida", "problems": invalid.Problems})
+			return
+		case errors.Is(err, registry.ErrServiceLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": "Se alcanzó el máximo de " + strconv.Itoa(maxServices) + " servicios"})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
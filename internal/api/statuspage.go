@@ -0,0 +1,28 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"health-check-app-micro/internal/statuspage"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusPageHandler sirve la página de estado en HTML. ?by= elige la clave
+// de tag por la que se agrupan los servicios (por defecto team).
+func StatusPageHandler(storage store.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var buf bytes.Buffer
+		if err := statuspage.Render(&buf, statuspage.Build(storage, c.Query("by"), time.Now())); err != nil {
+			utils.LogError("❌ Error generando la página de estado: " + err.Error())
+			c.String(http.StatusInternalServerError, "Error generando la página de estado")
+			return
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	}
+}
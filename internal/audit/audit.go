@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+)
+
+// Tipos de objeto que aparecen en Target.
+const (
+	TargetService     = "service"
+	TargetMaintenance = "maintenance"
+	TargetIncident    = "incident"
+	TargetAPIKey      = "apikey"
+)
+
+// runtimeFields son los campos del servicio que cambian con cada check y no
+// forman parte de su configuración.
+var runtimeFields = []string{"status", "lastCheck", "lastResult"}
+
+// Target arma el identificador del objeto auditado, por ejemplo
+// "service:api-gateway".
+func Target(kind, id string) string {
+	return kind + ":" + id
+}
+
+// Record guarda en la auditoría la entrada con el objeto antes y después
+// del cambio (nil si no existía o se eliminó). Si ambos existen y son
+// iguales no registra nada y devuelve false.
+func Record(storage store.Storage, entry models.AuditEntry, before, after any) bool {
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		utils.LogError("❌ Error serializando auditoría " + entry.Action + ": " + err.Error())
+		return false
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		utils.LogError("❌ Error serializando auditoría " + entry.Action + ": " + err.Error())
+		return false
+	}
+	if entry.Before != nil && entry.After != nil {
+		entry.Changes = Diff(entry.Before, entry.After)
+		if len(entry.Changes) == 0 {
+			return false
+		}
+	}
+	entry.ID = newID()
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	storage.AppendAudit(entry)
+	return true
+}
+
+// Diff compara dos objetos JSON y devuelve los campos de primer nivel que
+// cambiaron, ordenados por nombre.
+func Diff(before, after json.RawMessage) []models.AuditChange {
+	var b, a map[string]json.RawMessage
+	if json.Unmarshal(before, &b) != nil || json.Unmarshal(after, &a) != nil {
+		if bytes.Equal(before, after) {
+			return nil
+		}
+		return []models.AuditChange{{Field: "", Before: before, After: after}}
+	}
+
+	fields := make(map[string]bool, len(b)+len(a))
+	for k := range b {
+		fields[k] = true
+	}
+	for k := range a {
+		fields[k] = true
+	}
+	var changes []models.AuditChange
+	for k := range fields {
+		if !bytes.Equal(b[k], a[k]) {
+			changes = append(changes, models.AuditChange{Field: k, Before: b[k], After: a[k]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// Service devuelve la configuración del servicio tal como se audita, sin
+// el estado de los checks, o nil si el servicio no existe.
+func Service(m *models.Microservice) any {
+	if m == nil {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil
+	}
+	for _, f := range runtimeFields {
+		delete(fields, f)
+	}
+	return fields
+}
+
+// APIKey devuelve la key tal como se audita: sin su hash, solo con sus
+// primeros caracteres para notar una rotación.
+func APIKey(k models.APIKey) any {
+	fingerprint := k.Hash
+	if len(fingerprint) > 12 {
+		fingerprint = fingerprint[:12]
+	}
+	return struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Fingerprint string   `json:"fingerprint"`
+		Scopes      []string `json:"scopes"`
+		Source      string   `json:"source,omitempty"`
+		AllTenants  bool     `json:"allTenants,omitempty"`
+	}{k.ID, k.Name, fingerprint, k.Scopes, k.Source, k.AllTenants}
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "aud_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Orígenes de una API key.
+const (
+	SourceAPI    = "api"
+	SourceConfig = "config"
+	SourceEnv    = "env"
+)
+
+// keyPrefix identifica las keys generadas por esta app.
+const keyPrefix = "hc_"
+
+// prefixLen es cuántos caracteres de la key se guardan en claro para
+// reconocerla en los listados.
+const prefixLen = 10
+
+var (
+	ErrInvalidScope = errors.New("scope inválido: use read, register o admin")
+	ErrNoScopes     = errors.New("la key requiere al menos un scope")
+)
+
+// Métodos con los que se autentica una petición.
+const (
+	MethodAPIKey = "apikey"
+	MethodJWT    = "jwt"
+)
+
+// Config habilita y ajusta la autenticación de la API.
+type Config struct {
+	// PublicRead deja los endpoints de solo lectura abiertos sin key.
+	PublicRead bool
+	// BootstrapKey es una key admin en claro (ADMIN_API_KEY) que no se
+	// guarda en el store, para crear las primeras keys.
+	BootstrapKey string
+	// JWT, si no es nil, acepta además bearer tokens JWT.
+	JWT *JWTValidator
+}
+
+// Principal es quien hace la petición, ya autenticado.
+type Principal struct {
+	Name   string
+	Method string // apikey o jwt
+	Scopes []string
+	Tenant string // vacío es el tenant por defecto
+
+	// AllTenants permite operar en todos los tenants, no solo en Tenant.
+	AllTenants bool
+}
+
+// HasScope indica si el principal tiene el permiso indicado.
+func (p Principal) HasScope(scope string) bool {
+	return models.ScopesAllow(p.Scopes, scope)
+}
+
+// CanAccess indica si el principal puede operar en el tenant indicado.
+func (p Principal) CanAccess(tenant string) bool {
+	return p.AllTenants || p.Tenant == models.NormalizeTenant(tenant)
+}
+
+// Authenticator valida las credenciales presentadas: API keys contra el
+// store y, si está configurado, JWTs con su validador.
+type Authenticator struct {
+	storage    store.Storage
+	publicRead bool
+	bootstrap  *models.APIKey
+	jwt        *JWTValidator
+}
+
+// New crea el autenticador.
+func New(storage store.Storage, cfg Config) *Authenticator {
+	a := &Authenticator{storage: storage, publicRead: cfg.PublicRead, jwt: cfg.JWT}
+	if cfg.BootstrapKey != "" {
+		a.bootstrap = &models.APIKey{
+			ID:         "bootstrap",
+			Name:       "bootstrap",
+			Hash:       Hash(cfg.BootstrapKey),
+			Scopes:     []string{models.ScopeAdmin},
+			Source:     SourceEnv,
+			AllTenants: true,
+		}
+	}
+	return a
+}
+
+// PublicRead indica si las lecturas no requieren key.
+func (a *Authenticator) PublicRead() bool {
+	return a.publicRead
+}
+
+// Authenticate identifica al principal a partir de la credencial presentada:
+// un JWT si tiene esa forma y hay validador configurado, o una API key.
+func (a *Authenticator) Authenticate(credential string) (*Principal, bool) {
+	if credential == "" {
+		return nil, false
+	}
+	if a.jwt != nil && LooksLikeJWT(credential) {
+		p, err := a.jwt.Validate(credential)
+		if err != nil {
+			return nil, false
+		}
+		return p, true
+	}
+
+	hash := Hash(credential)
+	key := a.storage.APIKeyByHash(hash)
+	if a.bootstrap != nil && subtle.ConstantTimeCompare([]byte(hash), []byte(a.bootstrap.Hash)) == 1 {
+		key = a.bootstrap
+	}
+	if key == nil {
+		return nil, false
+	}
+	return &Principal{
+		Name:       key.Name,
+		Method:     MethodAPIKey,
+		Scopes:     append([]string(nil), key.Scopes...),
+		Tenant:     key.Tenant,
+		AllTenants: key.AllTenants,
+	}, true
+}
+
+// Create genera una key nueva, guarda su hash y devuelve el valor en claro,
+// que no se puede volver a obtener.
+func Create(storage store.Storage, name string, scopes []string) (string, models.APIKey, error) {
+	if err := ValidateScopes(scopes); err != nil {
+		return "", models.APIKey{}, err
+	}
+	secret, err := Generate()
+	if err != nil {
+		return "", models.APIKey{}, err
+	}
+	key := models.APIKey{
+		ID:        newID(),
+		Name:      name,
+		Prefix:    displayPrefix(secret),
+		Hash:      Hash(secret),
+		Scopes:    append([]string(nil), scopes...),
+		CreatedAt: time.Now(),
+		Source:    SourceAPI,
+	}
+	storage.SaveAPIKey(key)
+	return secret, key, nil
+}
+
+// ConfigKey es una key declarada en el archivo de configuración, solo con
+// su hash SHA-256 (por ejemplo `echo -n $KEY | sha256sum`). La key solo da
+// acceso a su tenant (vacío es el tenant por defecto), salvo que declare
+// allTenants.
+type ConfigKey struct {
+	Name       string   `json:"name"`
+	Hash       string   `json:"hash"`
+	Scopes     []string `json:"scopes"`
+	Tenant     string   `json:"tenant,omitempty"`
+	AllTenants bool     `json:"allTenants,omitempty"`
+}
+
+// SyncConfig reemplaza las keys del archivo de configuración por las
+// indicadas, sin tocar las creadas vía API.
+func SyncConfig(storage store.Storage, keys []ConfigKey) error {
+	if err := ValidateConfig(keys); err != nil {
+		return err
+	}
+	for _, existing := range storage.ListAPIKeys() {
+		if existing.Source == SourceConfig {
+			storage.DeleteAPIKey(existing.ID)
+		}
+	}
+	for _, k := range keys {
+		store.ForTenant(storage, k.Tenant).SaveAPIKey(models.APIKey{
+			ID:         "cfg_" + strings.ReplaceAll(models.QualifiedName(k.Tenant, k.Name), "/", "_"),
+			Name:       k.Name,
+			Hash:       strings.ToLower(k.Hash),
+			Scopes:     append([]string(nil), k.Scopes...),
+			Source:     SourceConfig,
+			AllTenants: k.AllTenants,
+		})
+	}
+	return nil
+}
+
+// ValidateConfig comprueba las keys del archivo de configuración sin
+// guardarlas.
+func ValidateConfig(keys []ConfigKey) error {
+	for _, k := range keys {
+		if k.Name == "" {
+			return errors.New("api key sin nombre en la configuración")
+		}
+		if b, err := hex.DecodeString(k.Hash); err != nil || len(b) != sha256.Size {
+			return errors.New("api key " + k.Name + ": el hash debe ser SHA-256 en hexadecimal")
+		}
+		if err := ValidateScopes(k.Scopes); err != nil {
+			return errors.New("api key " + k.Name + ": " + err.Error())
+		}
+		if !models.ValidTenant(k.Tenant) {
+			return errors.New("api key " + k.Name + ": tenant inválido " + k.Tenant)
+		}
+		if k.AllTenants && k.Tenant != "" {
+			return errors.New("api key " + k.Name + ": allTenants no admite tenant")
+		}
+	}
+	return nil
+}
+
+// ValidateScopes comprueba que la lista no esté vacía y sean scopes conocidos.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrNoScopes
+	}
+	for _, s := range scopes {
+		switch s {
+		case models.ScopeRead, models.ScopeRegister, models.ScopeAdmin:
+		default:
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+// Hash devuelve el SHA-256 en hexadecimal de la key.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate crea una key aleatoria de 256 bits.
+func Generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+func displayPrefix(key string) string {
+	if len(key) > prefixLen {
+		return key[:prefixLen]
+	}
+	return key
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "key_" + hex.EncodeToString(b)
+}
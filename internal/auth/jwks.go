@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"health-check-app-micro/pkg/utils"
+)
+
+const (
+	defaultJWKSRefresh = 10 * time.Minute
+	// jwksMinRefetch limita las descargas forzadas por un kid desconocido,
+	// para que tokens con kid inventado no disparen una descarga cada uno.
+	jwksMinRefetch = time.Minute
+	// maxJWKSBytes limita el tamaño del documento JWKS.
+	maxJWKSBytes = 1 << 20
+)
+
+// jwksCache guarda las claves públicas del JWKS. Si la descarga falla se
+// siguen usando las últimas claves conocidas, incluidas las de la copia
+// local en disco.
+type jwksCache struct {
+	url     string
+	path    string
+	refresh time.Duration
+	client  *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func newJWKSCache(url, path string, refresh time.Duration, client *http.Client) *jwksCache {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	c := &jwksCache{url: url, path: path, refresh: refresh, client: client, keys: map[string]any{}}
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if keys, err := parseJWKS(data); err == nil {
+				c.keys = keys
+			} else {
+				utils.LogError("❌ Copia local de JWKS inválida: " + err.Error())
+			}
+		}
+	}
+	return c
+}
+
+// key devuelve la clave con el kid indicado. Sin kid se acepta la única
+// clave del conjunto, si hay una sola.
+func (c *jwksCache) key(kid string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.fetchedAt) > c.refresh && time.Since(c.lastAttempt) > jwksMinRefetch {
+		c.fetchLocked()
+	}
+	if k, ok := c.lookupLocked(kid); ok {
+		return k, nil
+	}
+	// Puede ser una clave rotada recién: volver a descargar, con límite
+	if time.Since(c.lastAttempt) > jwksMinRefetch {
+		c.fetchLocked()
+		if k, ok := c.lookupLocked(kid); ok {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("clave %q no encontrada en el JWKS", kid)
+}
+
+func (c *jwksCache) lookupLocked(kid string) (any, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, k := range c.keys {
+			return k, true
+		}
+	}
+	k, ok := c.keys[kid]
+	return k, ok
+}
+
+// fetchLocked descarga el JWKS. Los errores solo se registran: se conservan
+// las claves anteriores.
+func (c *jwksCache) fetchLocked() {
+	c.lastAttempt = time.Now()
+	data, err := c.download()
+	if err == nil {
+		var keys map[string]any
+		if keys, err = parseJWKS(data); err == nil {
+			c.keys = keys
+			c.fetchedAt = time.Now()
+			if c.path != "" {
+				if err := writeCacheFile(c.path, data); err != nil {
+					utils.LogError("❌ Error guardando copia local de JWKS: " + err.Error())
+				}
+			}
+			return
+		}
+	}
+	utils.LogWarn("⚠️ Error actualizando JWKS desde " + c.url + ", se conservan las claves anteriores: " + err.Error())
+}
+
+func (c *jwksCache) download() ([]byte, error) {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+}
+
+func writeCacheFile(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS interpreta un documento JWKS y devuelve las claves de firma por
+// kid. Las claves de tipos no soportados se ignoran.
+func parseJWKS(data []byte) (map[string]any, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	keys := map[string]any{}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			utils.LogWarn("⚠️ Clave " + k.Kid + " del JWKS ignorada: " + err.Error())
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("el JWKS no tiene claves de firma utilizables")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curva %q no soportada", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("curva %q no soportada", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("clave Ed25519 inválida")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("tipo de clave %q no soportado", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("entero base64url inválido")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultRolesClaim es el claim del que se leen los roles si no se indica otro.
+const DefaultRolesClaim = "roles"
+
+// DefaultTenantClaim es el claim del que se lee el tenant si no se indica otro.
+const DefaultTenantClaim = "tenant"
+
+// jwtLeeway tolera diferencias de reloj con quien emite los tokens.
+const jwtLeeway = 30 * time.Second
+
+var (
+	hmacMethods = []string{"HS256", "HS384", "HS512"}
+	jwksMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+)
+
+// JWTConfig configura la validación de bearer tokens JWT. Se necesita al
+// menos JWKSURL (claves públicas, p. ej. las de jwt-service) o Secret (HMAC
+// compartido); con ambos se aceptan los dos tipos de firma.
+type JWTConfig struct {
+	JWKSURL       string
+	JWKSCachePath string        // copia local del JWKS para arrancar sin red
+	JWKSRefresh   time.Duration // cada cuánto se vuelve a descargar (por defecto 10m)
+	Secret        string
+	Issuer        string // si no está vacío, el claim iss debe coincidir
+	Audience      string // si no está vacío, el claim aud debe incluirlo
+
+	// RolesClaim es el claim con los roles; admite rutas con puntos como
+	// "realm_access.roles". Por defecto "roles".
+	RolesClaim string
+	// RoleScopes traduce cada rol a scopes. Por defecto los roles read,
+	// register y admin equivalen al scope del mismo nombre.
+	RoleScopes map[string][]string
+	// TenantClaim es el claim con el tenant del token, que solo da acceso a
+	// ese tenant; sin el claim, al tenant por defecto. Por defecto "tenant".
+	TenantClaim string
+
+	HTTPClient *http.Client
+}
+
+// JWTValidator valida tokens y los traduce a un Principal.
+type JWTValidator struct {
+	cfg    JWTConfig
+	jwks   *jwksCache
+	parser *jwt.Parser
+}
+
+// NewJWTValidator crea el validador. Si hay JWKS configurado carga la copia
+// local, si existe; la descarga se hace al validar el primer token.
+func NewJWTValidator(cfg JWTConfig) (*JWTValidator, error) {
+	if cfg.JWKSURL == "" && cfg.Secret == "" {
+		return nil, errors.New("JWT requiere una URL de JWKS o un secreto compartido")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = DefaultRolesClaim
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = DefaultTenantClaim
+	}
+	if cfg.RoleScopes == nil {
+		cfg.RoleScopes = map[string][]string{
+			models.ScopeRead:     {models.ScopeRead},
+			models.ScopeRegister: {models.ScopeRegister},
+			models.ScopeAdmin:    {models.ScopeAdmin},
+		}
+	}
+
+	var methods []string
+	if cfg.Secret != "" {
+		methods = append(methods, hmacMethods...)
+	}
+	v := &JWTValidator{cfg: cfg}
+	if cfg.JWKSURL != "" {
+		methods = append(methods, jwksMethods...)
+		v.jwks = newJWKSCache(cfg.JWKSURL, cfg.JWKSCachePath, cfg.JWKSRefresh, cfg.HTTPClient)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Validate verifica firma y claims del token y devuelve el principal con
+// los scopes de sus roles y el tenant de su claim. Un token válido sin roles
+// reconocidos o con un tenant inválido se rechaza.
+func (v *JWTValidator) Validate(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return nil, err
+	}
+
+	scopes := v.scopes(claims)
+	if len(scopes) == 0 {
+		return nil, errors.New("el token no tiene roles con permisos")
+	}
+	tenant := ""
+	if v := claimPath(claims, v.cfg.TenantClaim); v != nil {
+		s, ok := v.(string)
+		if !ok || !models.ValidTenant(s) {
+			return nil, errors.New("el token tiene un tenant inválido")
+		}
+		tenant = models.NormalizeTenant(s)
+	}
+	return &Principal{Name: subject(claims), Method: MethodJWT, Scopes: scopes, Tenant: tenant}, nil
+}
+
+// key elige la clave de verificación según el algoritmo del token.
+func (v *JWTValidator) key(t *jwt.Token) (any, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+		if v.cfg.Secret == "" {
+			return nil, errors.New("firma HMAC no habilitada")
+		}
+		return []byte(v.cfg.Secret), nil
+	}
+	if v.jwks == nil {
+		return nil, errors.New("firma asimétrica sin JWKS configurado")
+	}
+	kid, _ := t.Header["kid"].(string)
+	return v.jwks.key(kid)
+}
+
+func (v *JWTValidator) scopes(claims jwt.MapClaims) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, role := range stringList(claimPath(claims, v.cfg.RolesClaim)) {
+		for _, s := range v.cfg.RoleScopes[role] {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// claimPath sigue una ruta con puntos dentro de los claims.
+func claimPath(claims jwt.MapClaims, path string) any {
+	var cur any = map[string]any(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// stringList acepta un claim con un string (separado por espacios, como
+// "scope") o con una lista de strings.
+func stringList(v any) []string {
+	switch x := v.(type) {
+	case string:
+		return strings.Fields(x)
+	case []any:
+		out := make([]string, 0, len(x))
+		for _, item := range x {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func subject(claims jwt.MapClaims) string {
+	for _, c := range []string{"preferred_username", "email", "sub"} {
+		if s, ok := claims[c].(string); ok && s != "" {
+			return s
+		}
+	}
+	return "jwt"
+}
+
+// LooksLikeJWT distingue un JWT (tres segmentos separados por puntos) de una API key.
+func LooksLikeJWT(s string) bool {
+	return strings.Count(s, ".") == 2
+}
+
+// ParseRoleScopes interpreta un mapeo de roles como
+// "ROLE_ADMIN=admin;ROLE_OPS=register,read".
+func ParseRoleScopes(s string) (map[string][]string, error) {
+	out := map[string][]string{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, scopes, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("mapeo de rol inválido: %q", entry)
+		}
+		list := strings.Split(scopes, ",")
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+		if err := ValidateScopes(list); err != nil {
+			return nil, fmt.Errorf("rol %s: %w", role, err)
+		}
+		out[strings.TrimSpace(role)] = list
+	}
+	return out, nil
+}
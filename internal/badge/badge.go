@@ -0,0 +1,93 @@
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"unicode/utf8"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+)
+
+// Colores de los badges, los mismos que usa shields.io.
+const (
+	ColorBrightGreen = "#4c1"
+	ColorGreen       = "#97ca00"
+	ColorYellow      = "#dfb317"
+	ColorOrange      = "#fe7d37"
+	ColorRed         = "#e05d44"
+	ColorBlue        = "#007ec6"
+	ColorGrey        = "#9f9f9f"
+)
+
+// charWidth es el ancho medio de un carácter en Verdana 11px, la fuente de
+// los badges. Alcanza para que el texto no se corte sin medir cada glifo.
+const charWidth = 6.5
+
+// padding es el margen horizontal a cada lado del texto.
+const padding = 6
+
+// Render genera un badge SVG estilo shields.io ("flat") con la etiqueta a
+// la izquierda y el mensaje sobre el color indicado a la derecha.
+func Render(label, message, color string) []byte {
+	lw := textWidth(label) + 2*padding
+	mw := textWidth(message) + 2*padding
+	total := lw + mw
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, total, label, message)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, message)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, total)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		lw, lw, mw, color, total)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	writeText(&b, lw/2, label)
+	writeText(&b, lw+mw/2, message)
+	b.WriteString(`</g></svg>`)
+	return b.Bytes()
+}
+
+// writeText escribe el texto con la sombra que usan los badges flat.
+func writeText(b *bytes.Buffer, x int, text string) {
+	fmt.Fprintf(b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, x, text, x, text)
+}
+
+func textWidth(s string) int {
+	return int(float64(utf8.RuneCountInString(s))*charWidth + 0.5)
+}
+
+// StatusColor devuelve el color para el estado de un servicio.
+func StatusColor(status string) string {
+	switch status {
+	case "UP":
+		return ColorBrightGreen
+	case "DOWN":
+		return ColorRed
+	case report.StatusDegraded:
+		return ColorYellow
+	case models.StatusMaintenance:
+		return ColorBlue
+	default:
+		return ColorGrey
+	}
+}
+
+// UptimeColor devuelve el color para un porcentaje de uptime.
+func UptimeColor(percent float64) string {
+	switch {
+	case percent >= 99.9:
+		return ColorBrightGreen
+	case percent >= 99:
+		return ColorGreen
+	case percent >= 95:
+		return ColorYellow
+	case percent >= 90:
+		return ColorOrange
+	default:
+		return ColorRed
+	}
+}
@@ -2,78 +2,322 @@ package checker
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"health-check-app-micro/internal/events"
+	"health-check-app-micro/internal/graph"
+	"health-check-app-micro/internal/incidents"
+	"health-check-app-micro/internal/maintenance"
 	"health-check-app-micro/internal/models"
 	"health-check-app-micro/internal/notifier"
 	"health-check-app-micro/internal/store"
 	"health-check-app-micro/pkg/utils"
 )
 
-func StartHealthCheckLoop(storage *store.Store) {
-	for _, service := range storage.GetAll() {
-		go checkHealthWithFrequency(storage, service)
+// maxBodyBytes limita cuánto se lee de cada respuesta antes de descartarla,
+// para poder devolver la conexión al pool del transporte compartido.
+const maxBodyBytes = 64 << 10
+
+// snippetBytes es el tamaño máximo del fragmento de respuesta que se guarda
+// en un check fallido.
+const snippetBytes = 512
+
+var (
+	defaultScheduler     *Scheduler
+	defaultSchedulerOnce sync.Once
+)
+
+// DefaultConfig son los parámetros del scheduler por defecto. Se deben
+// fijar antes del primer uso; los valores en cero toman los por defecto.
+var DefaultConfig SchedulerConfig
+
+// DefaultScheduler devuelve el scheduler compartido por el proceso, creado
+// con DefaultConfig.
+func DefaultScheduler() *Scheduler {
+	defaultSchedulerOnce.Do(func() {
+		defaultScheduler = NewScheduler(DefaultConfig)
+	})
+	return defaultScheduler
+}
+
+// StartHealthCheckLoop programa en el scheduler por defecto todos los
+// servicios del store que aún no tengan checks programados. Cada servicio
+// se verifica a través de la vista de su tenant.
+func StartHealthCheckLoop(storage store.Storage) {
+	scheduler := DefaultScheduler()
+	for _, tenant := range store.Tenants(storage) {
+		view := store.ForTenant(storage, tenant)
+		for _, service := range view.GetAll() {
+			if !scheduler.IsScheduled(view, service.Name) {
+				scheduler.Schedule(view, service)
+			}
+		}
 	}
+	scheduler.Start()
 }
 
-func checkHealthWithFrequency(storage *store.Store, service *models.Microservice) {
-	frequency := time.Duration(service.Frequency) * time.Second
-	if frequency == 0 {
-		frequency = 30 * time.Second // default
+// Nueva función para registrar servicios después del loop inicial
+func RegisterNewService(storage store.Storage, service *models.Microservice) {
+	utils.LogInfo("🆕 Registrando nuevo servicio para monitoreo: " + service.QualifiedName())
+	DefaultScheduler().Schedule(storage, service)
+}
+
+// CheckNow ejecuta de forma síncrona el check del servicio indicado, actualiza
+// el store y dispara las notificaciones igual que un check programado.
+// Devuelve false si el servicio no existe.
+func CheckNow(storage store.Storage, name string) (models.CheckResult, bool) {
+	service := storage.Get(name)
+	if service == nil {
+		return models.CheckResult{}, false
 	}
-	
-	ticker := time.NewTicker(frequency)
-	defer ticker.Stop()
-	
-	// Ejecutar primera verificación inmediatamente
-	checkHealth(storage, service)
-	
-	for range ticker.C {
-		checkHealth(storage, service)
+	return checkHealth(DefaultScheduler().client, storage, service), true
+}
+
+// CheckAll ejecuta de forma síncrona el check de todos los servicios, con
+// tantas peticiones concurrentes como workers tenga el scheduler por defecto.
+// Los resultados se devuelven ordenados por nombre.
+func CheckAll(storage store.Storage) []models.CheckResult {
+	scheduler := DefaultScheduler()
+	services := storage.GetAll()
+
+	results := make([]models.CheckResult, 0, len(services))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, scheduler.workers)
+	for _, service := range services {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(service *models.Microservice) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := checkHealth(scheduler.client, storage, service)
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(service)
 	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
 }
 
-// Nueva función para registrar servicios después del loop inicial
-func RegisterNewService(storage *store.Store, service *models.Microservice) {
-	utils.LogInfo("🆕 Registrando nuevo servicio para monitoreo: " + service.Name)
-	go checkHealthWithFrequency(storage, service)
+// checkHealth verifica el servicio (una copia leída del store), guarda el
+// resultado y notifica si cambió el estado. El estado anterior lo devuelve
+// el store junto con la actualización, así dos checks simultáneos del mismo
+// servicio no notifican dos veces la misma transición.
+func checkHealth(client *http.Client, storage store.Storage, service *models.Microservice) models.CheckResult {
+	return checkService(client, storage, service, map[string]bool{})
 }
 
-func checkHealth(storage *store.Store, service *models.Microservice) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// checkService es checkHealth llevando los servicios ya verificados en esta
+// cadena de dependencias, para no repetirlos ni entrar en ciclos.
+func checkService(client *http.Client, storage store.Storage, service *models.Microservice, visited map[string]bool) models.CheckResult {
+	visited[service.Name] = true
+	result := probe(client, service.Endpoint)
+	result.Name = service.Name
+
+	// Antes de alertar, confirmar el estado de las dependencias: si alguna
+	// está caída, esta falla es un impacto y no la causa raíz
+	if result.Status != "UP" && len(service.DependsOn) > 0 {
+		verifyDependencies(client, storage, service, visited)
+		result.ImpactedBy = graph.FailingRootCauses(storage.GetAll(), service.Name)
+	}
+
+	// Dentro de una ventana de mantenimiento las fallas se marcan como
+	// MAINTENANCE y no generan alertas
+	window := maintenance.ActiveFor(storage, *service, result.Timestamp)
+	if window != nil {
+		result.Maintenance = true
+		if result.Status != "UP" {
+			result.Status = models.StatusMaintenance
+		}
+	}
+
+	oldStatus, ok := storage.RecordResult(service.Name, result)
+	if !ok {
+		// El servicio se eliminó mientras se verificaba
+		return result
 	}
-	
-	oldStatus := service.Status
-	resp, err := client.Get(service.Endpoint)
-	status := "DOWN"
-	
-	if err == nil && resp != nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == 200 {
-			status = "UP"
-			// Intentar parsear respuesta JSON para status detallado
-			var hs models.HealthStatus
-			if json.NewDecoder(resp.Body).Decode(&hs) == nil && hs.Status != "" {
-				status = hs.Status
+	result.PreviousStatus = oldStatus
+	status := result.Status
+	service.Status = status
+	service.LastCheck = result.Timestamp.Format(time.RFC3339)
+	service.LastResult = result.Clone()
+	publish(service, result)
+	utils.LogDebug("🔍 " + service.QualifiedName() + ": " + status + " en " + result.Duration().String())
+
+	if window != nil {
+		if oldStatus != status {
+			if status == "UP" {
+				// Recuperado durante el mantenimiento: cerrar sin notificar
+				incidents.Resolve(storage, service.Name, result.Timestamp)
 			}
+			utils.LogInfo("🔧 " + service.QualifiedName() + " está " + status + " (mantenimiento " + window.ID + ")")
 		}
+		return result
 	}
-	
-	lastCheck := time.Now().Format(time.RFC3339)
-	storage.UpdateService(service.Name, status, lastCheck)
 
-	// Notificar cambio de estado
+	// Notificar cambio de estado y llevar el incidente asociado
 	if oldStatus != status {
 		if status == "DOWN" {
-			notifier.Notify(service)
-			utils.LogError("⚠️ Servicio caído: " + service.Name)
-		} else if oldStatus == "DOWN" {
-			notifier.NotifyRecovery(service)
-			utils.LogInfo("✅ " + service.Name + " recuperado")
+			incident := incidents.Open(storage, service, result)
+			if incident.IsImpact() {
+				// La alerta la envía la dependencia caída, no cada dependiente
+				utils.LogWarn("🔗 Servicio caído por impacto de " + strings.Join(incident.ImpactedBy, ", ") + ": " + service.QualifiedName())
+				return result
+			}
+			notifyDown(storage, service, incident.ID, incidents.NotificationDown)
+			utils.LogWarn("⚠️ Servicio caído: " + service.QualifiedName() + " (" + result.ErrorClass + ": " + result.Error + ")")
+		} else if status == "UP" {
+			// Cualquier paso a UP cierra el incidente abierto: también al
+			// salir de un mantenimiento o de UNKNOWN tras editar el servicio
+			incident := incidents.Resolve(storage, service.Name, result.Timestamp)
+			alerted := incident != nil && len(incident.Notifications) > 0
+			// Solo se avisa la recuperación de lo que se alertó
+			if alerted || (incident == nil && oldStatus == "DOWN") {
+				notifier.NotifyRecoveryAsync(service)
+			}
+			if alerted {
+				incidents.RecordNotification(storage, incident.ID, incidents.NotificationRecovery, notifier.Recipients(service))
+			}
+			if incident != nil || oldStatus == "DOWN" {
+				utils.LogInfo("✅ " + service.QualifiedName() + " recuperado")
+			} else {
+				utils.LogInfo("🟢 " + service.QualifiedName() + " está " + status)
+			}
 		} else {
-			utils.LogInfo("🟢 " + service.Name + " está " + status)
+			utils.LogInfo("🟢 " + service.QualifiedName() + " está " + status)
 		}
+	} else if status == "DOWN" {
+		if len(result.ImpactedBy) == 0 {
+			// Las dependencias se recuperaron pero este sigue caído: ahora es
+			// la causa raíz y le toca su propia alerta
+			if incident := incidents.Escalate(storage, service.Name, result.Timestamp); incident != nil {
+				notifyDown(storage, service, incident.ID, incidents.NotificationDown)
+				utils.LogWarn("⚠️ Servicio caído: " + service.QualifiedName() + " (" + result.ErrorClass + ": " + result.Error + ")")
+				return result
+			}
+		}
+		// Sigue caído: repetir la alerta si nadie reconoció el incidente
+		if incident := incidents.DueForRepeat(storage, service.Name, result.Timestamp); incident != nil {
+			utils.LogDebug("🔁 Repitiendo alerta del incidente " + incident.ID + " de " + service.QualifiedName())
+			notifyDown(storage, service, incident.ID, incidents.NotificationRepeat)
+		}
+	}
+	return result
+}
+
+// publish emite el resultado, y el cambio de estado si lo hubo, en el
+// stream de eventos.
+func publish(service *models.Microservice, result models.CheckResult) {
+	broker := events.Default()
+	broker.Publish(events.Event{
+		Type:    events.TypeCheck,
+		Time:    result.Timestamp,
+		Service: service.Name,
+		Tenant:  service.Tenant,
+		Tags:    service.Tags,
+		Result:  result.Clone(),
+	})
+	if result.PreviousStatus != result.Status {
+		broker.Publish(events.Event{
+			Type:    events.TypeTransition,
+			Time:    result.Timestamp,
+			Service: service.Name,
+			Tenant:  service.Tenant,
+			Tags:    service.Tags,
+			From:    result.PreviousStatus,
+			To:      result.Status,
+			Result:  result.Clone(),
+		})
+	}
+}
+
+// notifyDown envía la alerta de caída, incluyendo los dependientes que están
+// fallando por este servicio, y la registra en el incidente.
+func notifyDown(storage store.Storage, service *models.Microservice, incidentID, kind string) {
+	impacted := graph.FailingDependents(storage.GetAll(), service.Name)
+	notifier.NotifyAsync(service, impacted...)
+	incidents.RecordNotification(storage, incidentID, kind, notifier.Recipients(service))
+}
+
+// verifyDependencies verifica en el momento las dependencias del servicio
+// que el store todavía no tiene como caídas, para que una caída recién
+// ocurrida se atribuya a la dependencia y no a cada dependiente.
+func verifyDependencies(client *http.Client, storage store.Storage, service *models.Microservice, visited map[string]bool) {
+	for _, name := range service.DependsOn {
+		if visited[name] {
+			continue
+		}
+		dep := storage.Get(name)
+		if dep == nil {
+			continue
+		}
+		if graph.Failing(dep.Status) {
+			visited[name] = true
+			continue
+		}
+		checkService(client, storage, dep, visited)
+	}
+}
+
+// probe hace la petición HTTP al endpoint y arma el resultado sin tocar el store.
+func probe(client *http.Client, endpoint string) models.CheckResult {
+	start := time.Now()
+	result := models.CheckResult{Status: "DOWN", Timestamp: start}
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.ErrorClass = classifyError(err)
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	// Vaciar un resto acotado del cuerpo para que la conexión pueda
+	// reutilizarse; si queda más, Close la descarta en lugar de leerlo todo
+	_, _ = io.CopyN(io.Discard, resp.Body, maxBodyBytes)
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.HTTPStatus = resp.StatusCode
+
+	switch {
+	case resp.StatusCode != 200:
+		result.ErrorClass = models.ErrorClassHTTP
+		result.Error = "respuesta HTTP " + strconv.Itoa(resp.StatusCode)
+	case err != nil:
+		result.ErrorClass = classifyError(err)
+		result.Error = err.Error()
+	default:
+		result.Status = "UP"
+		// Intentar parsear respuesta JSON para status detallado
+		var hs models.HealthStatus
+		if json.Unmarshal(body, &hs) == nil && hs.Status != "" {
+			result.Status = hs.Status
+		}
+		if result.Status != "UP" {
+			result.ErrorClass = models.ErrorClassAssertion
+			result.Error = "el servicio reporta estado " + hs.Status
+		}
+	}
+
+	if result.Status != "UP" {
+		result.ResponseSnippet = snippet(body)
+	}
+	return result
+}
+
+func snippet(body []byte) string {
+	if len(body) > snippetBytes {
+		body = body[:snippetBytes]
 	}
+	return strings.ToValidUTF8(string(body), "")
 }
@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"health-check-app-micro/internal/models"
+)
+
+// classifyError traduce el error de la petición HTTP a una de las clases
+// de models.CheckResult para poder agrupar y filtrar las caídas.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return models.ErrorClassDNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return models.ErrorClassRefused
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return models.ErrorClassTimeout
+	}
+	if isTLSError(err) {
+		return models.ErrorClassTLS
+	}
+	return models.ErrorClassNetwork
+}
+
+func isTLSError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		verification     *tls.CertificateVerificationError
+		recordHeader     tls.RecordHeaderError
+		alert            tls.AlertError
+	)
+	return errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &verification) ||
+		errors.As(err, &recordHeader) ||
+		errors.As(err, &alert)
+}
@@ -0,0 +1,299 @@
+package checker
+
+import (
+	"container/heap"
+	"net/http"
+	"sync"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+const (
+	defaultWorkers = 16
+	defaultTimeout = 10 * time.Second
+)
+
+// SchedulerConfig agrupa los parámetros del pool de workers.
+type SchedulerConfig struct {
+	Workers int           // cantidad de checks concurrentes
+	Timeout time.Duration // timeout de cada petición HTTP
+}
+
+// SchedulerStats es una foto del estado de la cola de checks.
+type SchedulerStats struct {
+	Running    bool    `json:"running"`
+	Workers    int     `json:"workers"`
+	InFlight   int     `json:"inFlight"`
+	Scheduled  int     `json:"scheduled"`  // checks en la cola (pendientes o futuros)
+	QueueDepth int     `json:"queueDepth"` // checks vencidos esperando un worker
+	LagSeconds float64 `json:"lagSeconds"` // atraso del check vencido más antiguo
+	LastLagMs  int64   `json:"lastLagMs"`  // atraso con el que se despachó el último check
+}
+
+// scheduledCheck es una entrada de la cola de prioridad, ordenada por vencimiento.
+// Solo guarda el nombre: en cada ejecución se lee una copia fresca del store.
+type scheduledCheck struct {
+	storage   store.Storage
+	name      string
+	frequency time.Duration
+	due       time.Time
+	index     int
+	requeue   bool // reprogramado mientras estaba en ejecución
+}
+
+type checkKey struct {
+	storage store.Storage
+	name    string
+}
+
+// checkQueue implementa heap.Interface sobre los checks programados.
+type checkQueue []*scheduledCheck
+
+func (q checkQueue) Len() int           { return len(q) }
+func (q checkQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+func (q checkQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *checkQueue) Push(x any) {
+	item := x.(*scheduledCheck)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *checkQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+// Scheduler mantiene una cola de prioridad de checks vencidos que es
+// consumida por un número fijo de workers. Todos los workers comparten
+// un único http.Client para reutilizar conexiones entre checks.
+type Scheduler struct {
+	mu       sync.Mutex
+	queue    checkQueue
+	entries  map[checkKey]*scheduledCheck
+	jobs     chan *scheduledCheck
+	wake     chan struct{}
+	client   *http.Client
+	workers  int
+	inFlight int
+	lastLag  time.Duration
+	started  bool
+}
+
+// NewScheduler crea un scheduler detenido; los workers arrancan con Start
+// o con el primer Schedule.
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        cfg.Workers * 4,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: cfg.Timeout,
+	}
+
+	return &Scheduler{
+		entries: make(map[checkKey]*scheduledCheck),
+		jobs:    make(chan *scheduledCheck),
+		wake:    make(chan struct{}, 1),
+		client:  &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		workers: cfg.Workers,
+	}
+}
+
+// Start lanza el despachador y los workers. Es idempotente.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+	go s.dispatch()
+}
+
+// Schedule agrega (o reprograma) el servicio para que se verifique de
+// inmediato y luego cada Frequency segundos. El servicio debe estar
+// registrado en storage; si deja de existir se desprograma solo, y mientras
+// esté pausado se saltean sus checks.
+func (s *Scheduler) Schedule(storage store.Storage, service *models.Microservice) {
+	s.Start()
+
+	s.mu.Lock()
+	key := checkKey{storage: storage, name: service.Name}
+	if item, ok := s.entries[key]; ok {
+		item.frequency = frequencyOf(service)
+		item.due = time.Now()
+		if item.index >= 0 {
+			heap.Fix(&s.queue, item.index)
+		} else {
+			item.requeue = true
+		}
+	} else {
+		item := &scheduledCheck{storage: storage, name: service.Name, frequency: frequencyOf(service), due: time.Now()}
+		s.entries[key] = item
+		heap.Push(&s.queue, item)
+	}
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+// IsScheduled indica si el servicio ya tiene checks programados en este scheduler.
+func (s *Scheduler) IsScheduled(storage store.Storage, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[checkKey{storage: storage, name: name}]
+	return ok
+}
+
+// Stats devuelve profundidad de cola y atraso actuales.
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	stats := SchedulerStats{
+		Running:   s.started,
+		Workers:   s.workers,
+		InFlight:  s.inFlight,
+		Scheduled: len(s.queue),
+		LastLagMs: s.lastLag.Milliseconds(),
+	}
+	for _, item := range s.queue {
+		if item.due.After(now) {
+			continue
+		}
+		stats.QueueDepth++
+		if lag := now.Sub(item.due).Seconds(); lag > stats.LagSeconds {
+			stats.LagSeconds = lag
+		}
+	}
+	return stats
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch saca de la cola los checks vencidos y los entrega a los workers.
+// Si todos los workers están ocupados el envío se bloquea y el atraso crece,
+// lo que queda reflejado en Stats.
+func (s *Scheduler) dispatch() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		var wait time.Duration = -1
+		var next *scheduledCheck
+		if len(s.queue) > 0 {
+			top := s.queue[0]
+			if d := time.Until(top.due); d > 0 {
+				wait = d
+			} else {
+				next = heap.Pop(&s.queue).(*scheduledCheck)
+			}
+		}
+		s.mu.Unlock()
+
+		if next != nil {
+			s.jobs <- next
+			continue
+		}
+
+		if wait < 0 {
+			<-s.wake
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *Scheduler) worker() {
+	for item := range s.jobs {
+		s.mu.Lock()
+		s.inFlight++
+		s.lastLag = time.Since(item.due)
+		storage, name := item.storage, item.name
+		s.mu.Unlock()
+
+		service := storage.Get(name)
+		if service != nil && !service.Paused {
+			checkHealth(s.client, storage, service)
+		}
+
+		s.mu.Lock()
+		s.inFlight--
+		if service == nil && !item.requeue {
+			delete(s.entries, checkKey{storage: storage, name: name})
+		} else {
+			if service != nil {
+				item.frequency = frequencyOf(service)
+			}
+			s.reschedule(item)
+		}
+		s.mu.Unlock()
+		s.signal()
+	}
+}
+
+// reschedule vuelve a encolar el check en su próximo vencimiento. Si el
+// scheduler va atrasado no intenta recuperar los checks perdidos.
+// Caller MUST hold s.mu.
+func (s *Scheduler) reschedule(item *scheduledCheck) {
+	if item.requeue {
+		item.requeue = false
+		heap.Push(&s.queue, item)
+		return
+	}
+	now := time.Now()
+	next := item.due.Add(item.frequency)
+	if next.Before(now) {
+		next = now
+	}
+	item.due = next
+	heap.Push(&s.queue, item)
+}
+
+func frequencyOf(service *models.Microservice) time.Duration {
+	frequency := time.Duration(service.Frequency) * time.Second
+	if frequency <= 0 {
+		frequency = time.Duration(models.DefaultFrequency) * time.Second
+	}
+	return frequency
+}
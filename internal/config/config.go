@@ -0,0 +1,415 @@
+// Package config arma la configuración de la aplicación a partir de un
+// archivo, variables de entorno y flags de línea de comandos.
+//
+// Cada valor se toma de la primera fuente que lo defina, en este orden:
+// flags, variables de entorno, archivo y valores por defecto.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// minFrequency es la frecuencia mínima de un servicio.
+const minFrequency = 10 * time.Second
+
+// Config es la configuración de la aplicación. En el archivo los campos se
+// escriben con sus nombres en JSON y las duraciones como texto ("10s").
+type Config struct {
+	Listen          string   `json:"listen"`          // dirección del servidor HTTP, p. ej. ":8080"
+	ShutdownTimeout Duration `json:"shutdownTimeout"` // espera de las peticiones en curso al apagar
+	LogLevel        string   `json:"logLevel"`        // debug, info, warn o error
+
+	Store     StoreConfig     `json:"store"`
+	Checker   CheckerConfig   `json:"checker"`
+	Services  ServicesConfig  `json:"services"`
+	Notifier  NotifierConfig  `json:"notifier"`
+	Auth      AuthConfig      `json:"auth"`
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	Limits    LimitsConfig    `json:"limits"`
+	Incidents IncidentsConfig `json:"incidents"`
+}
+
+// StoreConfig indica dónde se guardan los servicios.
+type StoreConfig struct {
+	Backend string `json:"backend"` // json o sqlite
+	Path    string `json:"path"`    // vacío toma el archivo por defecto del backend
+}
+
+// CheckerConfig son los parámetros de los checks.
+type CheckerConfig struct {
+	Workers   int      `json:"workers"`   // checks concurrentes
+	Timeout   Duration `json:"timeout"`   // timeout de cada petición HTTP
+	Frequency Duration `json:"frequency"` // frecuencia de los servicios que no indican una
+}
+
+// ServicesConfig es el archivo de servicios que se registran al arrancar.
+type ServicesConfig struct {
+	ConfigPath     string   `json:"configPath"`     // vacío toma services-config.json
+	Strict         bool     `json:"strict"`         // no arrancar si el archivo es inválido o no existe
+	ReloadInterval Duration `json:"reloadInterval"` // cada cuánto se revisa; 0 solo recarga con SIGHUP
+}
+
+// NotifierConfig es el servidor SMTP de las alertas. Sin host ni puerto las
+// alertas solo se escriben en el log.
+type NotifierConfig struct {
+	SMTPHost     string `json:"smtpHost"`
+	SMTPPort     string `json:"smtpPort"`
+	SMTPUser     string `json:"smtpUser"`
+	SMTPPassword string `json:"smtpPassword"`
+	SMTPFrom     string `json:"smtpFrom"` // vacío usa SMTPUser
+}
+
+// AuthConfig es la autenticación de la API. Deshabilitada, la API es
+// abierta.
+type AuthConfig struct {
+	Enabled     bool      `json:"enabled"`
+	PublicRead  bool      `json:"publicRead"`  // lectura sin credenciales
+	AdminAPIKey string    `json:"adminApiKey"` // key de arranque con acceso total
+	JWT         JWTConfig `json:"jwt"`
+}
+
+// JWTConfig es la validación de JWT. Sin jwksUrl ni secret no se aceptan
+// JWT.
+type JWTConfig struct {
+	JWKSURL       string `json:"jwksUrl"`
+	JWKSCachePath string `json:"jwksCachePath"`
+	Secret        string `json:"secret"` // HS256
+	Issuer        string `json:"issuer"`
+	Audience      string `json:"audience"`
+	RolesClaim    string `json:"rolesClaim"`
+	TenantClaim   string `json:"tenantClaim"`
+	RoleScopes    string `json:"roleScopes"` // "ROL=scope,scope;ROL=scope"
+}
+
+// Enabled indica si hay una fuente de claves para validar JWT.
+func (j JWTConfig) Enabled() bool {
+	return j.JWKSURL != "" || j.Secret != ""
+}
+
+// RateLimitConfig limita las peticiones de cada cliente autenticado y, antes
+// de autenticar, de cada IP. Con rps 0 no hay límite.
+type RateLimitConfig struct {
+	RPS     float64 `json:"rps"`
+	Burst   int     `json:"burst"`
+	IPRPS   float64 `json:"ipRps"`
+	IPBurst int     `json:"ipBurst"`
+}
+
+// LimitsConfig son los límites de tamaño de la API.
+type LimitsConfig struct {
+	MaxBodyBytes         int64 `json:"maxBodyBytes"`
+	MaxServicesPerTenant int   `json:"maxServicesPerTenant"` // 0 sin límite
+}
+
+// IncidentsConfig son los parámetros de los incidentes.
+type IncidentsConfig struct {
+	RepeatInterval Duration `json:"repeatInterval"` // repetición de alertas sin reconocer; 0 no repite
+}
+
+// Duration es un time.Duration que en el archivo se escribe como texto.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default devuelve la configuración por defecto.
+func Default() Config {
+	return Config{
+		Listen:          ":8080",
+		ShutdownTimeout: Duration(10 * time.Second),
+		LogLevel:        "info",
+		Store:           StoreConfig{Backend: store.BackendJSON},
+		Checker: CheckerConfig{
+			Workers:   16,
+			Timeout:   Duration(10 * time.Second),
+			Frequency: Duration(30 * time.Second),
+		},
+		Services:  ServicesConfig{ReloadInterval: Duration(10 * time.Second)},
+		RateLimit: RateLimitConfig{RPS: 10, Burst: 20, IPRPS: 50, IPBurst: 100},
+		Limits: LimitsConfig{
+			MaxBodyBytes:         1 << 20,
+			MaxServicesPerTenant: 1000,
+		},
+		Incidents: IncidentsConfig{RepeatInterval: Duration(15 * time.Minute)},
+	}
+}
+
+// setting es un valor que se puede definir por variable de entorno y por
+// flag.
+type setting struct {
+	flag, env, usage string
+	set              func(c *Config, value string) error
+	isBool           bool
+}
+
+var settings = []setting{
+	{flag: "listen", env: "LISTEN_ADDR", usage: "dirección del servidor HTTP", set: setString(func(c *Config) *string { return &c.Listen })},
+	{flag: "shutdown-timeout", env: "SHUTDOWN_TIMEOUT", usage: "espera de las peticiones en curso al apagar", set: setDuration(func(c *Config) *Duration { return &c.ShutdownTimeout })},
+	{flag: "log-level", env: "LOG_LEVEL", usage: "nivel de log: debug, info, warn o error", set: setString(func(c *Config) *string { return &c.LogLevel })},
+	{flag: "store-backend", env: "STORE_BACKEND", usage: "almacenamiento: json o sqlite", set: setString(func(c *Config) *string { return &c.Store.Backend })},
+	{flag: "store-path", env: "STORE_PATH", usage: "archivo del almacenamiento", set: setString(func(c *Config) *string { return &c.Store.Path })},
+	{flag: "checker-workers", env: "CHECKER_WORKERS", usage: "checks concurrentes", set: setInt(func(c *Config) *int { return &c.Checker.Workers })},
+	{flag: "check-timeout", env: "CHECK_TIMEOUT", usage: "timeout de cada check", set: setDuration(func(c *Config) *Duration { return &c.Checker.Timeout })},
+	{flag: "check-frequency", env: "CHECK_FREQUENCY", usage: "frecuencia de los servicios que no indican una", set: setDuration(func(c *Config) *Duration { return &c.Checker.Frequency })},
+	{flag: "services-config", env: "SERVICES_CONFIG_PATH", usage: "archivo de servicios a registrar", set: setString(func(c *Config) *string { return &c.Services.ConfigPath })},
+	{flag: "config-strict", env: "CONFIG_STRICT", usage: "no arrancar con un archivo de servicios inválido o ausente", set: setBool(func(c *Config) *bool { return &c.Services.Strict }), isBool: true},
+	{flag: "config-reload-interval", env: "CONFIG_RELOAD_INTERVAL", usage: "cada cuánto se revisa el archivo de servicios (0 desactiva)", set: setDuration(func(c *Config) *Duration { return &c.Services.ReloadInterval })},
+	{flag: "smtp-host", env: "SMTP_HOST", usage: "servidor SMTP", set: setString(func(c *Config) *string { return &c.Notifier.SMTPHost })},
+	{flag: "smtp-port", env: "SMTP_PORT", usage: "puerto SMTP", set: setString(func(c *Config) *string { return &c.Notifier.SMTPPort })},
+	{flag: "smtp-user", env: "SMTP_USER", usage: "usuario SMTP", set: setString(func(c *Config) *string { return &c.Notifier.SMTPUser })},
+	{flag: "smtp-password", env: "SMTP_PASSWORD", usage: "contraseña SMTP", set: setString(func(c *Config) *string { return &c.Notifier.SMTPPassword })},
+	{flag: "smtp-from", env: "SMTP_FROM", usage: "remitente de las alertas", set: setString(func(c *Config) *string { return &c.Notifier.SMTPFrom })},
+	{flag: "auth-enabled", env: "AUTH_ENABLED", usage: "exigir credenciales en la API", set: setBool(func(c *Config) *bool { return &c.Auth.Enabled }), isBool: true},
+	{flag: "auth-public-read", env: "AUTH_PUBLIC_READ", usage: "permitir lectura sin credenciales", set: setBool(func(c *Config) *bool { return &c.Auth.PublicRead }), isBool: true},
+	{flag: "admin-api-key", env: "ADMIN_API_KEY", usage: "API key de arranque con acceso total", set: setString(func(c *Config) *string { return &c.Auth.AdminAPIKey })},
+	{flag: "jwt-jwks-url", env: "JWT_JWKS_URL", usage: "URL del JWKS para validar JWT", set: setString(func(c *Config) *string { return &c.Auth.JWT.JWKSURL })},
+	{flag: "jwt-jwks-cache-path", env: "JWT_JWKS_CACHE_PATH", usage: "archivo de caché del JWKS", set: setString(func(c *Config) *string { return &c.Auth.JWT.JWKSCachePath })},
+	{flag: "jwt-secret", env: "JWT_SECRET", usage: "secreto HS256 para validar JWT", set: setString(func(c *Config) *string { return &c.Auth.JWT.Secret })},
+	{flag: "jwt-issuer", env: "JWT_ISSUER", usage: "issuer esperado de los JWT", set: setString(func(c *Config) *string { return &c.Auth.JWT.Issuer })},
+	{flag: "jwt-audience", env: "JWT_AUDIENCE", usage: "audience esperada de los JWT", set: setString(func(c *Config) *string { return &c.Auth.JWT.Audience })},
+	{flag: "jwt-roles-claim", env: "JWT_ROLES_CLAIM", usage: "claim con los roles", set: setString(func(c *Config) *string { return &c.Auth.JWT.RolesClaim })},
+	{flag: "jwt-tenant-claim", env: "JWT_TENANT_CLAIM", usage: "claim con el tenant", set: setString(func(c *Config) *string { return &c.Auth.JWT.TenantClaim })},
+	{flag: "jwt-role-scopes", env: "JWT_ROLE_SCOPES", usage: "scopes de cada rol: ROL=scope,scope;ROL=scope", set: setString(func(c *Config) *string { return &c.Auth.JWT.RoleScopes })},
+	{flag: "rate-limit-rps", env: "RATE_LIMIT_RPS", usage: "peticiones por segundo de cada cliente (0 desactiva)", set: setFloat(func(c *Config) *float64 { return &c.RateLimit.RPS })},
+	{flag: "rate-limit-burst", env: "RATE_LIMIT_BURST", usage: "ráfaga de peticiones de cada cliente", set: setInt(func(c *Config) *int { return &c.RateLimit.Burst })},
+	{flag: "rate-limit-ip-rps", env: "RATE_LIMIT_IP_RPS", usage: "peticiones por segundo de cada IP antes de autenticar (0 desactiva)", set: setFloat(func(c *Config) *float64 { return &c.RateLimit.IPRPS })},
+	{flag: "rate-limit-ip-burst", env: "RATE_LIMIT_IP_BURST", usage: "ráfaga de peticiones de cada IP", set: setInt(func(c *Config) *int { return &c.RateLimit.IPBurst })},
+	{flag: "max-body-bytes", env: "MAX_BODY_BYTES", usage: "tamaño máximo del cuerpo de las peticiones", set: setInt64(func(c *Config) *int64 { return &c.Limits.MaxBodyBytes })},
+	{flag: "max-services-per-tenant", env: "MAX_SERVICES_PER_TENANT", usage: "servicios por tenant registrables vía API (0 sin límite)", set: setInt(func(c *Config) *int { return &c.Limits.MaxServicesPerTenant })},
+	{flag: "incident-repeat-interval", env: "INCIDENT_REPEAT_INTERVAL", usage: "cada cuánto se repite la alerta de un incidente sin reconocer (0 no repite)", set: setDuration(func(c *Config) *Duration { return &c.Incidents.RepeatInterval })},
+}
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("se esperaba un número entero")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setInt64(field func(*Config) *int64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("se esperaba un número entero")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setFloat(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("se esperaba un número")
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func setBool(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("se esperaba true o false")
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setDuration(field func(*Config) *Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		return field(c).UnmarshalText([]byte(value))
+	}
+}
+
+// Load arma la configuración con los argumentos de la línea de comandos
+// (sin el nombre del programa). El archivo se indica con -config o
+// APP_CONFIG; sin él solo se usan variables de entorno y flags.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("health-check-app", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("APP_CONFIG"), "archivo de configuración (.json, .yaml o .toml)")
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flags []flagValue
+	for _, s := range settings {
+		record := func(value string) error {
+			flags = append(flags, flagValue{s, value})
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.flag, s.usage, record)
+		} else {
+			fs.Func(s.flag, s.usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.set(&cfg, value); err != nil {
+			return nil, fmt.Errorf("%s=%q: %w", s.env, value, err)
+		}
+	}
+	for _, f := range flags {
+		if err := f.setting.set(&cfg, f.value); err != nil {
+			return nil, fmt.Errorf("-%s=%q: %w", f.setting.flag, f.value, err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadFile pisa la configuración con los valores del archivo, en el formato
+// que indique su extensión (.yaml, .yml, .toml o JSON en cualquier otro
+// caso). Los campos desconocidos son un error.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var tree any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		err = json.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if tree == nil {
+		return nil
+	}
+	// Todos los formatos se decodifican como JSON para compartir los tags
+	if data, err = json.Marshal(tree); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate informa todos los valores inválidos de la configuración.
+func (c Config) Validate() error {
+	var problems []string
+	if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+		problems = append(problems, fmt.Sprintf("listen %q: se esperaba host:puerto, p. ej. :8080", c.Listen))
+	}
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdownTimeout debe ser mayor a 0")
+	}
+	if _, err := utils.ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, "logLevel: "+err.Error())
+	}
+	switch strings.ToLower(c.Store.Backend) {
+	case "", store.BackendJSON, store.BackendSQLite:
+	default:
+		problems = append(problems, fmt.Sprintf("store.backend %q: debe ser %s o %s", c.Store.Backend, store.BackendJSON, store.BackendSQLite))
+	}
+	if c.Checker.Workers <= 0 {
+		problems = append(problems, "checker.workers debe ser mayor a 0")
+	}
+	if c.Checker.Timeout <= 0 {
+		problems = append(problems, "checker.timeout debe ser mayor a 0")
+	}
+	if time.Duration(c.Checker.Frequency) < minFrequency || c.Checker.Frequency%Duration(time.Second) != 0 {
+		problems = append(problems, fmt.Sprintf("checker.frequency debe ser de al menos %s, en segundos enteros", minFrequency))
+	}
+	if c.Services.ReloadInterval < 0 {
+		problems = append(problems, "services.reloadInterval no puede ser negativo")
+	}
+	if (c.Notifier.SMTPHost == "") != (c.Notifier.SMTPPort == "") {
+		problems = append(problems, "notifier: smtpHost y smtpPort se configuran juntos")
+	}
+	if c.Auth.JWT.RoleScopes != "" {
+		if _, err := auth.ParseRoleScopes(c.Auth.JWT.RoleScopes); err != nil {
+			problems = append(problems, "auth.jwt.roleScopes: "+err.Error())
+		}
+	}
+	if c.RateLimit.RPS < 0 {
+		problems = append(problems, "rateLimit.rps no puede ser negativo")
+	}
+	if c.RateLimit.RPS > 0 && c.RateLimit.Burst <= 0 {
+		problems = append(problems, "rateLimit.burst debe ser mayor a 0")
+	}
+	if c.RateLimit.IPRPS < 0 {
+		problems = append(problems, "rateLimit.ipRps no puede ser negativo")
+	}
+	if c.RateLimit.IPRPS > 0 && c.RateLimit.IPBurst <= 0 {
+		problems = append(problems, "rateLimit.ipBurst debe ser mayor a 0")
+	}
+	if c.Limits.MaxBodyBytes <= 0 {
+		problems = append(problems, "limits.maxBodyBytes debe ser mayor a 0")
+	}
+	if c.Limits.MaxServicesPerTenant < 0 {
+		problems = append(problems, "limits.maxServicesPerTenant no puede ser negativo")
+	}
+	if c.Incidents.RepeatInterval < 0 {
+		problems = append(problems, "incidents.repeatInterval no puede ser negativo")
+	}
+	if len(problems) > 0 {
+		return errors.New("configuración de la aplicación inválida: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"health-check-app-micro/internal/models"
+)
+
+// Tipos de evento.
+const (
+	TypeCheck      = "check"      // resultado de cada check
+	TypeTransition = "transition" // cambio de estado de un servicio
+)
+
+// DefaultBufferSize es cuántos eventos recientes guarda el broker por
+// defecto para reanudar un stream con Last-Event-ID.
+const DefaultBufferSize = 1024
+
+// subscriberBuffer es cuántos eventos puede tener pendientes un suscriptor
+// antes de que se lo desconecte por lento.
+const subscriberBuffer = 64
+
+// Event es un cambio publicado en el stream.
+type Event struct {
+	ID      uint64              `json:"id"`
+	Type    string              `json:"type"`
+	Time    time.Time           `json:"time"`
+	Service string              `json:"service"`
+	Tenant  string              `json:"tenant,omitempty"`
+	Tags    []string            `json:"tags,omitempty"`
+	From    string              `json:"from,omitempty"` // solo en transition
+	To      string              `json:"to,omitempty"`   // solo en transition
+	Result  *models.CheckResult `json:"result,omitempty"`
+}
+
+// Filter limita los eventos que recibe un suscriptor. Solo recibe eventos
+// de su tenant; sin servicios ni tags recibe todos los del tenant.
+type Filter struct {
+	Tenant   string   // vacío es el tenant por defecto
+	Services []string // alguno de estos servicios
+	Tags     []string // todos estos tags
+}
+
+// Match indica si el evento pasa el filtro.
+func (f Filter) Match(e Event) bool {
+	if e.Tenant != f.Tenant {
+		return false
+	}
+	if len(f.Services) > 0 {
+		found := false
+		for _, s := range f.Services {
+			if s == e.Service {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	svc := models.Microservice{Tags: e.Tags}
+	return svc.HasTags(f.Tags)
+}
+
+// Subscription recibe los eventos publicados que pasan su filtro. C se
+// cierra si el suscriptor no consume a tiempo o al cancelar la suscripción.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter Filter
+}
+
+// Broker reparte los eventos a los suscriptores y guarda los más recientes
+// en un buffer circular.
+type Broker struct {
+	mu     sync.Mutex
+	nextID uint64
+	buffer []Event // circular, de capacidad fija
+	start  int     // posición del evento más antiguo
+	count  int
+	subs   map[*Subscription]struct{}
+}
+
+var (
+	defaultBroker     *Broker
+	defaultBrokerOnce sync.Once
+)
+
+// Default devuelve el broker compartido por el proceso.
+func Default() *Broker {
+	defaultBrokerOnce.Do(func() {
+		defaultBroker = NewBroker(DefaultBufferSize)
+	})
+	return defaultBroker
+}
+
+// NewBroker crea un broker que recuerda los últimos size eventos.
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Broker{
+		nextID: 1,
+		buffer: make([]Event, size),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish asigna ID y hora al evento, lo guarda en el buffer y lo envía a
+// los suscriptores. Nunca bloquea: un suscriptor con la cola llena se
+// desconecta y puede reanudar desde su último ID.
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.ID = b.nextID
+	b.nextID++
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	pos := (b.start + b.count) % len(b.buffer)
+	b.buffer[pos] = e
+	if b.count < len(b.buffer) {
+		b.count++
+	} else {
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+	return e
+}
+
+// Subscribe registra un suscriptor y devuelve, junto con la suscripción,
+// los eventos del buffer posteriores a lastID que pasan el filtro. Con
+// lastID 0 no se devuelve historial.
+func (b *Broker) Subscribe(filter Filter, lastID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	if lastID > 0 {
+		for i := 0; i < b.count; i++ {
+			e := b.buffer[(b.start+i)%len(b.buffer)]
+			if e.ID > lastID && filter.Match(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+	b.subs[sub] = struct{}{}
+	return sub, backlog
+}
+
+// Unsubscribe da de baja al suscriptor y cierra su canal.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
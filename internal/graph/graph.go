@@ -0,0 +1,234 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"health-check-app-micro/internal/models"
+)
+
+// StatusMissing es el estado de un nodo referenciado en dependsOn que no
+// está registrado.
+const StatusMissing = "MISSING"
+
+// Node es un servicio dentro del grafo de dependencias.
+type Node struct {
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	DependsOn  []string `json:"dependsOn,omitempty"`
+	ImpactedBy []string `json:"impactedBy,omitempty"` // dependencias caídas que explican su falla
+	RootCause  bool     `json:"rootCause"`            // caído sin dependencias caídas
+}
+
+// Edge va del servicio dependiente a su dependencia.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph es la topología de servicios con su estado actual.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Failing indica si el estado cuenta como falla a efectos de dependencias.
+// Un servicio en mantenimiento también explica la falla de sus dependientes.
+func Failing(status string) bool {
+	return status == "DOWN" || status == models.StatusMaintenance
+}
+
+// FailingRootCauses devuelve las dependencias (directas o transitivas) de
+// name que están fallando y no dependen a su vez de otra dependencia caída.
+// Si devuelve algo, la falla de name es un impacto y no la causa raíz.
+func FailingRootCauses(services map[string]*models.Microservice, name string) []string {
+	service, ok := services[name]
+	if !ok {
+		return nil
+	}
+
+	roots := map[string]bool{}
+	visited := map[string]bool{name: true}
+	var walk func(deps []string)
+	walk = func(deps []string) {
+		for _, dep := range deps {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			d, ok := services[dep]
+			if !ok {
+				continue
+			}
+			if !Failing(d.Status) {
+				continue
+			}
+			if len(failingDeps(services, d, map[string]bool{name: true, dep: true})) == 0 {
+				roots[dep] = true
+			}
+			walk(d.DependsOn)
+		}
+	}
+	walk(service.DependsOn)
+
+	return sortedKeys(roots)
+}
+
+// failingDeps indica qué dependencias transitivas de service están fallando,
+// sin volver a recorrer los nodos de skip.
+func failingDeps(services map[string]*models.Microservice, service *models.Microservice, skip map[string]bool) []string {
+	var out []string
+	for _, dep := range service.DependsOn {
+		if skip[dep] {
+			continue
+		}
+		skip[dep] = true
+		d, ok := services[dep]
+		if !ok {
+			continue
+		}
+		if Failing(d.Status) {
+			out = append(out, dep)
+		}
+		out = append(out, failingDeps(services, d, skip)...)
+	}
+	return out
+}
+
+// FailingDependents devuelve los servicios que dependen (directa o
+// transitivamente) de name y que están fallando.
+func FailingDependents(services map[string]*models.Microservice, name string) []string {
+	dependents := map[string]bool{}
+	var walk func(target string)
+	walk = func(target string) {
+		for other, s := range services {
+			if dependents[other] || other == name {
+				continue
+			}
+			for _, dep := range s.DependsOn {
+				if dep == target {
+					dependents[other] = true
+					walk(other)
+					break
+				}
+			}
+		}
+	}
+	walk(name)
+
+	var out []string
+	for _, d := range sortedKeys(dependents) {
+		if Failing(services[d].Status) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Build arma el grafo con el estado actual de cada servicio.
+func Build(services map[string]*models.Microservice) Graph {
+	g := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	missing := map[string]bool{}
+
+	for _, name := range sortedServiceNames(services) {
+		s := services[name]
+		node := Node{Name: name, Status: s.Status, DependsOn: s.DependsOn}
+		if Failing(s.Status) {
+			node.ImpactedBy = FailingRootCauses(services, name)
+			node.RootCause = len(node.ImpactedBy) == 0
+		}
+		g.Nodes = append(g.Nodes, node)
+		for _, dep := range s.DependsOn {
+			g.Edges = append(g.Edges, Edge{From: name, To: dep})
+			if _, ok := services[dep]; !ok {
+				missing[dep] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(missing) {
+		g.Nodes = append(g.Nodes, Node{Name: name, Status: StatusMissing})
+	}
+	return g
+}
+
+// FindCycle devuelve un ciclo de dependencias (por ejemplo a -> b -> a) o nil
+// si el grafo es acíclico. deps mapea cada servicio a sus dependencias.
+func FindCycle(deps map[string][]string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	var cycle []string
+
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		state[n] = inProgress
+		stack = append(stack, n)
+		for _, d := range deps[n] {
+			switch state[d] {
+			case inProgress:
+				for i, s := range stack {
+					if s == d {
+						cycle = append(append([]string{}, stack[i:]...), d)
+						return true
+					}
+				}
+			case unvisited:
+				if visit(d) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = done
+		return false
+	}
+
+	names := make([]string, 0, len(deps))
+	for n := range deps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if state[n] == unvisited && visit(n) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// CycleError describe un ciclo encontrado por FindCycle.
+func CycleError(cycle []string) error {
+	return fmt.Errorf("dependencia circular: %s", strings.Join(cycle, " -> "))
+}
+
+// DependencyMap extrae dependsOn de cada servicio.
+func DependencyMap(services map[string]*models.Microservice) map[string][]string {
+	deps := make(map[string][]string, len(services))
+	for name, s := range services {
+		deps[name] = s.DependsOn
+	}
+	return deps
+}
+
+func sortedServiceNames(services map[string]*models.Microservice) []string {
+	names := make([]string, 0, len(services))
+	for n := range services {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
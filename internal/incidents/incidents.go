@@ -0,0 +1,202 @@
+package incidents
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Tipos de notificación registrados en un incidente.
+const (
+	NotificationDown     = "down"
+	NotificationRepeat   = "repeat"
+	NotificationRecovery = "recovery"
+)
+
+// RepeatInterval es cada cuánto se repite la alerta de un incidente abierto
+// que nadie reconoció. Con 0 no se repite.
+var RepeatInterval = 15 * time.Minute
+
+var (
+	ErrNotFound = errors.New("incidente no encontrado")
+	ErrResolved = errors.New("el incidente ya está resuelto")
+)
+
+// Open abre un incidente para el servicio caído. Si ya hay uno abierto lo
+// devuelve sin crear otro.
+func Open(storage store.Storage, service *models.Microservice, result models.CheckResult) models.Incident {
+	if active := storage.ActiveIncident(service.Name); active != nil {
+		return *active
+	}
+
+	incident := models.Incident{
+		ID:         newID(),
+		Service:    service.Name,
+		Status:     models.IncidentOpen,
+		StartedAt:  result.Timestamp,
+		FirstError: result.Clone(),
+		ImpactedBy: append([]string(nil), result.ImpactedBy...),
+		Timeline: []models.IncidentEvent{{
+			Time:    result.Timestamp,
+			Type:    models.EventOpened,
+			Message: describe(result),
+		}},
+	}
+	storage.CreateIncident(incident)
+	return incident
+}
+
+// Resolve cierra el incidente abierto del servicio y calcula su duración.
+// Devuelve nil si el servicio no tenía un incidente abierto.
+func Resolve(storage store.Storage, serviceName string, at time.Time) *models.Incident {
+	active := storage.ActiveIncident(serviceName)
+	if active == nil {
+		return nil
+	}
+	resolved, _ := storage.UpdateIncident(active.ID, func(i *models.Incident) {
+		i.Status = models.IncidentResolved
+		i.ResolvedAt = &at
+		i.DurationSeconds = int64(at.Sub(i.StartedAt).Seconds())
+		i.Timeline = append(i.Timeline, models.IncidentEvent{
+			Time:    at,
+			Type:    models.EventResolved,
+			Message: "servicio recuperado tras " + at.Sub(i.StartedAt).Round(time.Second).String(),
+		})
+	})
+	return resolved
+}
+
+// RecordNotification agrega a la línea de tiempo una notificación enviada.
+func RecordNotification(storage store.Storage, id, kind string, recipients []string) {
+	now := time.Now()
+	storage.UpdateIncident(id, func(i *models.Incident) {
+		i.Notifications = append(i.Notifications, models.NotificationRecord{
+			Time:       now,
+			Kind:       kind,
+			Recipients: append([]string(nil), recipients...),
+		})
+		i.Timeline = append(i.Timeline, models.IncidentEvent{
+			Time:    now,
+			Type:    models.EventNotification,
+			Message: fmt.Sprintf("notificación %s enviada a %d destinatarios", kind, len(recipients)),
+		})
+	})
+}
+
+// DueForRepeat devuelve el incidente abierto del servicio si corresponde
+// repetir la alerta: no fue reconocido y pasó RepeatInterval desde la
+// última notificación.
+func DueForRepeat(storage store.Storage, serviceName string, now time.Time) *models.Incident {
+	if RepeatInterval <= 0 {
+		return nil
+	}
+	active := storage.ActiveIncident(serviceName)
+	if active == nil || active.Acknowledged || active.IsImpact() {
+		return nil
+	}
+	last := active.StartedAt
+	if n := len(active.Notifications); n > 0 {
+		last = active.Notifications[n-1].Time
+	}
+	if now.Sub(last) < RepeatInterval {
+		return nil
+	}
+	return active
+}
+
+// Escalate convierte un incidente por impacto en uno propio del servicio,
+// cuando sus dependencias se recuperaron pero él sigue caído. Devuelve el
+// incidente actualizado, o nil si no había que escalar nada.
+func Escalate(storage store.Storage, serviceName string, at time.Time) *models.Incident {
+	active := storage.ActiveIncident(serviceName)
+	if active == nil || !active.IsImpact() {
+		return nil
+	}
+	updated, _ := storage.UpdateIncident(active.ID, func(i *models.Incident) {
+		i.Timeline = append(i.Timeline, models.IncidentEvent{
+			Time:    at,
+			Type:    models.EventEscalated,
+			Message: "dependencias recuperadas (" + strings.Join(i.ImpactedBy, ", ") + "), el servicio sigue caído",
+		})
+		i.ImpactedBy = nil
+	})
+	return updated
+}
+
+// Acknowledge marca el incidente como reconocido por actor, lo que detiene
+// las alertas repetidas. Reconocerlo de nuevo no cambia quién lo reconoció.
+func Acknowledge(storage store.Storage, id, actor, note string) (*models.Incident, error) {
+	now := time.Now()
+	resolved := false
+	updated, ok := storage.UpdateIncident(id, func(i *models.Incident) {
+		if !i.IsOpen() {
+			resolved = true
+			return
+		}
+		if !i.Acknowledged {
+			i.Acknowledged = true
+			i.AcknowledgedBy = actor
+			i.AcknowledgedAt = &now
+			i.Timeline = append(i.Timeline, models.IncidentEvent{
+				Time:    now,
+				Type:    models.EventAcknowledged,
+				Message: "incidente reconocido",
+				Actor:   actor,
+			})
+		}
+		if note != "" {
+			addNote(i, now, actor, note)
+		}
+	})
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if resolved {
+		return nil, ErrResolved
+	}
+	return updated, nil
+}
+
+// AddNote agrega una nota al incidente.
+func AddNote(storage store.Storage, id, author, text string) (*models.Incident, error) {
+	updated, ok := storage.UpdateIncident(id, func(i *models.Incident) {
+		addNote(i, time.Now(), author, text)
+	})
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return updated, nil
+}
+
+func addNote(i *models.Incident, at time.Time, author, text string) {
+	i.Notes = append(i.Notes, models.IncidentNote{Time: at, Author: author, Text: text})
+	i.Timeline = append(i.Timeline, models.IncidentEvent{
+		Time:    at,
+		Type:    models.EventNote,
+		Message: text,
+		Actor:   author,
+	})
+}
+
+func describe(result models.CheckResult) string {
+	msg := "servicio DOWN"
+	if result.ErrorClass != "" {
+		msg = fmt.Sprintf("servicio DOWN (%s: %s)", result.ErrorClass, result.Error)
+	}
+	if len(result.ImpactedBy) > 0 {
+		msg += "; impactado por " + strings.Join(result.ImpactedBy, ", ")
+	}
+	return msg
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "inc_" + hex.EncodeToString(b)
+}
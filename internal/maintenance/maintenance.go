@@ -0,0 +1,96 @@
+package maintenance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Orígenes de una ventana de mantenimiento.
+const (
+	SourceAPI    = "api"
+	SourceConfig = "config"
+)
+
+// ActiveFor devuelve la ventana de mantenimiento vigente para el servicio en
+// el instante at, o nil si no hay ninguna.
+func ActiveFor(storage store.Storage, service models.Microservice, at time.Time) *models.MaintenanceWindow {
+	for _, w := range storage.ListMaintenance() {
+		if w.Covers(service) && w.ActiveAt(at) {
+			window := w
+			return &window
+		}
+	}
+	return nil
+}
+
+// Validate comprueba que la ventana sea coherente antes de guardarla.
+func Validate(w models.MaintenanceWindow) error {
+	if w.Start.IsZero() || w.End.IsZero() {
+		return errors.New("la ventana requiere inicio y fin")
+	}
+	if !w.End.After(w.Start) {
+		return errors.New("el fin de la ventana debe ser posterior al inicio")
+	}
+	switch w.Recurrence {
+	case models.RecurrenceNone:
+	case models.RecurrenceDaily:
+		if w.End.Sub(w.Start) >= 24*time.Hour {
+			return errors.New("una ventana diaria debe durar menos de 24h")
+		}
+	case models.RecurrenceWeekly:
+		if w.End.Sub(w.Start) >= 7*24*time.Hour {
+			return errors.New("una ventana semanal debe durar menos de 7 días")
+		}
+	default:
+		return errors.New("recurrencia inválida: use daily o weekly")
+	}
+	return nil
+}
+
+// SyncConfig reemplaza las ventanas definidas en el archivo de configuración
+// por las indicadas, sin tocar las creadas vía API. Cada ventana se guarda en
+// el tenant que indique.
+func SyncConfig(storage store.Storage, windows []models.MaintenanceWindow) error {
+	if err := ValidateConfig(windows); err != nil {
+		return err
+	}
+	for _, existing := range storage.ListMaintenance() {
+		if existing.Source == SourceConfig {
+			storage.DeleteMaintenance(existing.ID)
+		}
+	}
+	for _, w := range windows {
+		w.Source = SourceConfig
+		if w.ID == "" {
+			w.ID = NewID()
+		}
+		store.ForTenant(storage, w.Tenant).SaveMaintenance(w)
+	}
+	return nil
+}
+
+// ValidateConfig comprueba las ventanas del archivo de configuración sin
+// guardarlas.
+func ValidateConfig(windows []models.MaintenanceWindow) error {
+	for _, w := range windows {
+		if err := Validate(w); err != nil {
+			return errors.New("ventana " + w.ID + ": " + err.Error())
+		}
+		if !models.ValidTenant(w.Tenant) {
+			return errors.New("ventana " + w.ID + ": tenant inválido " + w.Tenant)
+		}
+	}
+	return nil
+}
+
+// NewID genera un identificador para una ventana creada vía API.
+func NewID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "mnt_" + hex.EncodeToString(b)
+}
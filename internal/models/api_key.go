@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Permisos que puede tener una API key. admin incluye a todos los demás.
+const (
+	ScopeRead     = "read"     // consultar servicios, incidentes y métricas
+	ScopeRegister = "register" // registrar servicios y operar checks, incidentes y mantenimiento
+	ScopeAdmin    = "admin"    // administrar API keys
+)
+
+// APIKey es una credencial de acceso a la API. Solo se guarda el hash
+// SHA-256 de la key; el valor en claro se muestra una única vez al crearla.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"` // primeros caracteres, para reconocerla
+	Hash      string    `json:"hash"`   // SHA-256 en hexadecimal
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	Source    string    `json:"source,omitempty"` // api, config o env
+	Tenant    string    `json:"tenant,omitempty"` // vacío es el tenant por defecto
+
+	// AllTenants da acceso a todos los tenants. Solo lo tienen la key de
+	// ADMIN_API_KEY y las del archivo de configuración que lo declaren.
+	AllTenants bool `json:"allTenants,omitempty"`
+}
+
+// HasScope indica si la key tiene el permiso indicado.
+func (k APIKey) HasScope(scope string) bool {
+	return ScopesAllow(k.Scopes, scope)
+}
+
+// ScopesAllow indica si la lista de scopes concede scope, teniendo en
+// cuenta que admin los incluye a todos.
+func ScopesAllow(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone devuelve una copia profunda de la key.
+func (k APIKey) Clone() APIKey {
+	k.Scopes = append([]string(nil), k.Scopes...)
+	return k
+}
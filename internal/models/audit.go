@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Acciones que quedan registradas en la auditoría.
+const (
+	AuditServiceRegister   = "service.register"
+	AuditServiceUpdate     = "service.update"
+	AuditServiceDelete     = "service.delete"
+	AuditServicePause      = "service.pause"
+	AuditServiceResume     = "service.resume"
+	AuditMaintenanceCreate = "maintenance.create"
+	AuditMaintenanceUpdate = "maintenance.update"
+	AuditMaintenanceDelete = "maintenance.delete"
+	AuditIncidentAck       = "incident.ack"
+	AuditAPIKeyCreate      = "apikey.create"
+	AuditAPIKeyUpdate      = "apikey.update"
+	AuditAPIKeyDelete      = "apikey.delete"
+)
+
+// AuditActorConfig es el autor de los cambios aplicados desde el archivo de
+// configuración.
+const AuditActorConfig = "config"
+
+// AuditEntry es un cambio de configuración: quién lo hizo, cuándo, desde
+// dónde y cómo quedó el objeto antes y después. Las entradas no se
+// modifican; solo se descartan al vencer su retención.
+type AuditEntry struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Tenant   string          `json:"tenant,omitempty"`
+	Actor    string          `json:"actor"`
+	SourceIP string          `json:"sourceIp,omitempty"`
+	Action   string          `json:"action"`
+	Target   string          `json:"target"` // p. ej. "service:api-gateway"
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Changes  []AuditChange   `json:"changes,omitempty"`
+}
+
+// AuditChange es un campo que cambió entre Before y After.
+type AuditChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Clone devuelve una copia profunda de la entrada.
+func (e AuditEntry) Clone() AuditEntry {
+	e.Before = append(json.RawMessage(nil), e.Before...)
+	e.After = append(json.RawMessage(nil), e.After...)
+	changes := make([]AuditChange, len(e.Changes))
+	for i, c := range e.Changes {
+		c.Before = append(json.RawMessage(nil), c.Before...)
+		c.After = append(json.RawMessage(nil), c.After...)
+		changes[i] = c
+	}
+	e.Changes = changes
+	return e
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Clases de error con las que se etiqueta un check fallido.
+const (
+	ErrorClassTimeout   = "timeout"
+	ErrorClassRefused   = "refused"
+	ErrorClassDNS       = "dns"
+	ErrorClassTLS       = "tls"
+	ErrorClassHTTP      = "http"      // respuesta con código distinto de 200
+	ErrorClassAssertion = "assertion" // 200 pero el cuerpo reporta un estado distinto de UP
+	ErrorClassNetwork   = "network"   // cualquier otro error de conexión
+)
+
+// CheckResult es el resultado de una verificación de salud.
+type CheckResult struct {
+	Name            string    `json:"name"`
+	Status          string    `json:"status"`
+	PreviousStatus  string    `json:"previousStatus,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+	DurationMs      int64     `json:"durationMs"`
+	HTTPStatus      int       `json:"httpStatus,omitempty"`
+	ErrorClass      string    `json:"errorClass,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	ResponseSnippet string    `json:"responseSnippet,omitempty"`
+	Maintenance     bool      `json:"maintenance,omitempty"` // ejecutado dentro de una ventana de mantenimiento
+	ImpactedBy      []string  `json:"impactedBy,omitempty"`  // dependencias caídas que causan esta falla
+}
+
+// Duration devuelve la latencia del check.
+func (r CheckResult) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+// Clone devuelve una copia profunda del resultado.
+func (r *CheckResult) Clone() *CheckResult {
+	c := *r
+	if c.ImpactedBy != nil {
+		c.ImpactedBy = append([]string(nil), c.ImpactedBy...)
+	}
+	return &c
+}
+
+// DailyStats resume los checks de un servicio en un día calendario UTC. Se
+// conserva aunque el historial detallado se recorte, para calcular uptime
+// de ventanas largas.
+type DailyStats struct {
+	Date        time.Time `json:"date"` // medianoche UTC del día
+	Checks      int       `json:"checks"`
+	Up          int       `json:"up"`
+	Down        int       `json:"down"`
+	Maintenance int       `json:"maintenance"` // checks en mantenimiento, no cuentan para el uptime
+}
+
+// Day devuelve la medianoche UTC del día de t.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Add suma el resultado al resumen.
+func (d *DailyStats) Add(r CheckResult) {
+	d.Checks++
+	switch {
+	case r.Maintenance:
+		d.Maintenance++
+	case r.Status == "UP":
+		d.Up++
+	default:
+		d.Down++
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+// Clone devuelve una copia profunda del servicio, para que quien la reciba
+// pueda leerla o modificarla sin compartir memoria con el store.
+func (m Microservice) Clone() Microservice {
+	if m.Emails != nil {
+		m.Emails = append([]string(nil), m.Emails...)
+	}
+	if m.Tags != nil {
+		m.Tags = append([]string(nil), m.Tags...)
+	}
+	if m.DependsOn != nil {
+		m.DependsOn = append([]string(nil), m.DependsOn...)
+	}
+	if m.LastResult != nil {
+		m.LastResult = m.LastResult.Clone()
+	}
+	return m
+}
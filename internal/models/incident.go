@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Estados de un incidente.
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// Tipos de evento en la línea de tiempo de un incidente.
+const (
+	EventOpened       = "opened"
+	EventNotification = "notification"
+	EventAcknowledged = "acknowledged"
+	EventNote         = "note"
+	EventEscalated    = "escalated" // la dependencia se recuperó pero el servicio sigue caído
+	EventResolved     = "resolved"
+)
+
+// Incident agrupa el período en que un servicio estuvo DOWN, desde la
+// primera caída hasta su recuperación.
+type Incident struct {
+	ID              string               `json:"id"`
+	Service         string               `json:"service"`
+	Status          string               `json:"status"`
+	StartedAt       time.Time            `json:"startedAt"`
+	ResolvedAt      *time.Time           `json:"resolvedAt,omitempty"`
+	DurationSeconds int64                `json:"durationSeconds,omitempty"` // calculado al resolverse
+	FirstError      *CheckResult         `json:"firstError,omitempty"`
+	ImpactedBy      []string             `json:"impactedBy,omitempty"` // causa raíz en una dependencia; sin alertas propias
+	Notifications   []NotificationRecord `json:"notifications"`
+	Acknowledged    bool                 `json:"acknowledged"`
+	AcknowledgedBy  string               `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt  *time.Time           `json:"acknowledgedAt,omitempty"`
+	Notes           []IncidentNote       `json:"notes"`
+	Timeline        []IncidentEvent      `json:"timeline"`
+}
+
+// NotificationRecord registra una notificación enviada por el incidente.
+type NotificationRecord struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"` // down, repeat, recovery
+	Recipients []string  `json:"recipients"`
+}
+
+// IncidentNote es una nota libre agregada por el equipo de guardia.
+type IncidentNote struct {
+	Time   time.Time `json:"time"`
+	Author string    `json:"author"`
+	Text   string    `json:"text"`
+}
+
+// IncidentEvent es una entrada de la línea de tiempo del incidente.
+type IncidentEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+	Actor   string    `json:"actor,omitempty"`
+}
+
+// IsImpact indica si el incidente se debe a la caída de una dependencia y
+// no es la causa raíz.
+func (i Incident) IsImpact() bool {
+	return len(i.ImpactedBy) > 0
+}
+
+// IsOpen indica si el incidente sigue activo.
+func (i Incident) IsOpen() bool {
+	return i.Status == IncidentOpen
+}
+
+// Clone devuelve una copia profunda del incidente.
+func (i Incident) Clone() Incident {
+	if i.ResolvedAt != nil {
+		t := *i.ResolvedAt
+		i.ResolvedAt = &t
+	}
+	if i.AcknowledgedAt != nil {
+		t := *i.AcknowledgedAt
+		i.AcknowledgedAt = &t
+	}
+	if i.FirstError != nil {
+		i.FirstError = i.FirstError.Clone()
+	}
+	if i.ImpactedBy != nil {
+		i.ImpactedBy = append([]string(nil), i.ImpactedBy...)
+	}
+	notifications := make([]NotificationRecord, len(i.Notifications))
+	for k, n := range i.Notifications {
+		n.Recipients = append([]string(nil), n.Recipients...)
+		notifications[k] = n
+	}
+	i.Notifications = notifications
+	i.Notes = append([]IncidentNote{}, i.Notes...)
+	i.Timeline = append([]IncidentEvent{}, i.Timeline...)
+	return i
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// StatusMaintenance es el estado que toma un servicio que falla durante una
+// ventana de mantenimiento.
+const StatusMaintenance = "MAINTENANCE"
+
+// Recurrencias soportadas por las ventanas de mantenimiento.
+const (
+	RecurrenceNone   = ""
+	RecurrenceDaily  = "daily"
+	RecurrenceWeekly = "weekly"
+)
+
+// MaintenanceWindow es un período en el que las caídas de los servicios
+// afectados no generan alertas ni cuentan para el uptime. Si no indica
+// servicios ni tags aplica a todos los servicios.
+type MaintenanceWindow struct {
+	ID         string    `json:"id"`
+	Tenant     string    `json:"tenant,omitempty"` // solo aplica a los servicios de este tenant
+	Services   []string  `json:"services,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Recurrence string    `json:"recurrence,omitempty"` // daily o weekly, repitiendo [Start, End)
+	Reason     string    `json:"reason,omitempty"`
+	Source     string    `json:"source,omitempty"` // api o config
+}
+
+// ActiveAt indica si la ventana está vigente en el instante t.
+func (w MaintenanceWindow) ActiveAt(t time.Time) bool {
+	if t.Before(w.Start) {
+		return false
+	}
+	length := w.End.Sub(w.Start)
+	if length <= 0 {
+		return false
+	}
+
+	var period time.Duration
+	switch w.Recurrence {
+	case RecurrenceDaily:
+		period = 24 * time.Hour
+	case RecurrenceWeekly:
+		period = 7 * 24 * time.Hour
+	default:
+		return t.Before(w.End)
+	}
+	return t.Sub(w.Start)%period < length
+}
+
+// Expired indica si una ventana sin recurrencia ya terminó.
+func (w MaintenanceWindow) Expired(t time.Time) bool {
+	return w.Recurrence == RecurrenceNone && !t.Before(w.End)
+}
+
+// Covers indica si la ventana aplica al servicio, por nombre o por tag.
+func (w MaintenanceWindow) Covers(service Microservice) bool {
+	if len(w.Services) == 0 && len(w.Tags) == 0 {
+		return true
+	}
+	for _, name := range w.Services {
+		if name == service.Name {
+			return true
+		}
+	}
+	for _, tag := range w.Tags {
+		if service.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone devuelve una copia profunda de la ventana.
+func (w MaintenanceWindow) Clone() MaintenanceWindow {
+	w.Services = append([]string(nil), w.Services...)
+	w.Tags = append([]string(nil), w.Tags...)
+	return w
+}
@@ -1,10 +1,56 @@
 package models
 
+import "strings"
+
+// DefaultFrequency es la frecuencia, en segundos, de los servicios que no
+// indican una válida (menos de 10 segundos). Se configura al arrancar.
+var DefaultFrequency = 30
+
 type Microservice struct {
 	Name      string   `json:"name"`
+	Tenant    string   `json:"tenant,omitempty"` // equipo dueño del servicio; vacío es el tenant por defecto
 	Endpoint  string   `json:"endpoint"`
 	Frequency int      `json:"frequency"` // en segundos
 	Emails    []string `json:"emails"`
 	Status    string   `json:"status"`
 	LastCheck string   `json:"lastCheck"`
+	Tags      []string `json:"tags,omitempty"`      // p. ej. "team:perfil", "env:prod"
+	DependsOn []string `json:"dependsOn,omitempty"` // servicios de los que depende
+	Critical  bool     `json:"critical,omitempty"`  // su caída deja la plataforma DOWN
+	Paused    bool     `json:"paused,omitempty"`    // sin checks programados hasta reanudarlo
+	Source    string   `json:"source,omitempty"`    // api o config
+
+	LastResult *CheckResult `json:"lastResult,omitempty"` // detalle del último check
+}
+
+// HasTag indica si el servicio tiene el tag indicado.
+func (m Microservice) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTags indica si el servicio tiene todos los tags indicados.
+func (m Microservice) HasTags(tags []string) bool {
+	for _, t := range tags {
+		if !m.HasTag(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// TagValue devuelve el valor de un tag con forma "clave:valor", por ejemplo
+// TagValue("team") para "team:perfil".
+func (m Microservice) TagValue(key string) (string, bool) {
+	prefix := key + ":"
+	for _, t := range m.Tags {
+		if strings.HasPrefix(t, prefix) {
+			return t[len(prefix):], true
+		}
+	}
+	return "", false
 }
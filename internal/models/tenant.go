@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultTenant es el tenant de lo registrado sin tenant, que se sirve en las
+// rutas sin prefijo /t/:tenant. En los datos se guarda como tenant vacío.
+const DefaultTenant = "default"
+
+// tenantSeparator separa el tenant del nombre del servicio en el store.
+const tenantSeparator = "/"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeTenant devuelve el tenant tal como se guarda: vacío para el
+// tenant por defecto.
+func NormalizeTenant(tenant string) string {
+	if tenant == DefaultTenant {
+		return ""
+	}
+	return tenant
+}
+
+// ValidTenant indica si el nombre sirve como tenant: minúsculas, dígitos y
+// guiones, hasta 63 caracteres. El vacío es el tenant por defecto.
+func ValidTenant(tenant string) bool {
+	return tenant == "" || tenantPattern.MatchString(tenant)
+}
+
+// ValidServiceName indica si el nombre puede usarse para un servicio; no
+// puede contener el separador de tenant.
+func ValidServiceName(name string) bool {
+	return name != "" && !strings.Contains(name, tenantSeparator)
+}
+
+// QualifiedName devuelve la clave global del servicio name del tenant,
+// "tenant/name", o solo name en el tenant por defecto.
+func QualifiedName(tenant, name string) string {
+	if tenant = NormalizeTenant(tenant); tenant == "" {
+		return name
+	}
+	return tenant + tenantSeparator + name
+}
+
+// SplitName separa una clave global en tenant y nombre.
+func SplitName(qualified string) (tenant, name string) {
+	if i := strings.Index(qualified, tenantSeparator); i >= 0 {
+		return qualified[:i], qualified[i+1:]
+	}
+	return "", qualified
+}
+
+// QualifiedName devuelve el nombre del servicio con su tenant, para
+// mostrarlo fuera del tenant (por ejemplo en las alertas).
+func (m Microservice) QualifiedName() string {
+	return QualifiedName(m.Tenant, m.Name)
+}
@@ -5,46 +5,108 @@ import (
 	"health-check-app-micro/internal/models"
 	"health-check-app-micro/pkg/utils"
 	"net/smtp"
-	"os"
+	"strings"
 )
 
-func Notify(service *models.Microservice) {
-	sendNotification(service, fmt.Sprintf("⚠️ ALERTA: El microservicio %s está CAÍDO", service.Name),
-		fmt.Sprintf("El microservicio %s está actualmente DOWN.\nEndpoint: %s\nÚltimo check: %s",
-			service.Name, service.Endpoint, service.LastCheck))
+// SMTPConfig es el servidor por el que se envían las alertas.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string // remitente; vacío usa User
+}
+
+// SMTP es el servidor de las alertas, que se configura al arrancar. Sin host
+// ni puerto las alertas solo se escriben en el log.
+var SMTP SMTPConfig
+
+// Notify avisa que el servicio está caído. impacted son los servicios que
+// dependen de él y están fallando por su causa; no reciben alerta propia.
+func Notify(service *models.Microservice, impacted ...string) {
+	subject, body := downMessage(service, impacted)
+	sendNotification(service, subject, body)
 }
 
 func NotifyRecovery(service *models.Microservice) {
-	sendNotification(service, fmt.Sprintf("✅ RECUPERADO: El microservicio %s está UP", service.Name),
+	subject, body := recoveryMessage(service)
+	sendNotification(service, subject, body)
+}
+
+// NotifyAsync es Notify a través de la cola de envíos, sin esperar al SMTP.
+func NotifyAsync(service *models.Microservice, impacted ...string) {
+	subject, body := downMessage(service, impacted)
+	enqueue(service, subject, body)
+}
+
+// NotifyRecoveryAsync es NotifyRecovery a través de la cola de envíos.
+func NotifyRecoveryAsync(service *models.Microservice) {
+	subject, body := recoveryMessage(service)
+	enqueue(service, subject, body)
+}
+
+func downMessage(service *models.Microservice, impacted []string) (string, string) {
+	body := fmt.Sprintf("El microservicio %s está actualmente DOWN.\nEndpoint: %s\nÚltimo check: %s",
+		service.QualifiedName(), service.Endpoint, service.LastCheck) + resultDetails(service.LastResult)
+	if len(impacted) > 0 {
+		body += "\nServicios impactados: " + strings.Join(impacted, ", ")
+	}
+	return fmt.Sprintf("⚠️ ALERTA: El microservicio %s está CAÍDO", service.QualifiedName()), body
+}
+
+func recoveryMessage(service *models.Microservice) (string, string) {
+	return fmt.Sprintf("✅ RECUPERADO: El microservicio %s está UP", service.QualifiedName()),
 		fmt.Sprintf("El microservicio %s ha recuperado su estado normal.\nEndpoint: %s\nÚltimo check: %s",
-			service.Name, service.Endpoint, service.LastCheck))
+			service.QualifiedName(), service.Endpoint, service.LastCheck) + resultDetails(service.LastResult)
+}
+
+// resultDetails arma el bloque con el detalle del último check para el cuerpo del correo.
+func resultDetails(result *models.CheckResult) string {
+	if result == nil {
+		return ""
+	}
+	details := fmt.Sprintf("\nLatencia: %dms", result.DurationMs)
+	if result.HTTPStatus != 0 {
+		details += fmt.Sprintf("\nCódigo HTTP: %d", result.HTTPStatus)
+	}
+	if result.ErrorClass != "" {
+		details += fmt.Sprintf("\nTipo de error: %s\nError: %s", result.ErrorClass, result.Error)
+	}
+	if result.ResponseSnippet != "" {
+		details += "\nRespuesta: " + result.ResponseSnippet
+	}
+	return details
 }
 
 func sendNotification(service *models.Microservice, subject, body string) {
-	// Obtener configuración SMTP de variables de entorno
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUser := os.Getenv("SMTP_USER")
-	smtpPass := os.Getenv("SMTP_PASSWORD")
+	smtpHost := SMTP.Host
+	smtpPort := SMTP.Port
+	smtpUser := SMTP.User
+	smtpPass := SMTP.Password
+	from := SMTP.From
+	if from == "" {
+		from = smtpUser
+	}
+	recipients := Recipients(service)
 	
 	if smtpHost == "" || smtpPort == "" {
 		// Si no hay SMTP configurado, solo log en consola
-		for _, email := range service.Emails {
+		for _, email := range recipients {
 			utils.LogInfo(fmt.Sprintf("📧 [CONSOLE] %s -> %s: %s", subject, email, body))
 		}
 		return
 	}
 	
 	// Implementación real de envío por SMTP con formato RFC 822 correcto
-	for _, email := range service.Emails {
+	for _, email := range recipients {
 		// Formato correcto del mensaje según RFC 822
-		msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", smtpUser, email, subject, body)
+		msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", from, email, subject, body)
 		msgBytes := []byte(msg)
 		
 		auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
 		addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
 		
-		err := smtp.SendMail(addr, auth, smtpUser, []string{email}, msgBytes)
+		err := smtp.SendMail(addr, auth, from, []string{email}, msgBytes)
 		if err != nil {
 			utils.LogError(fmt.Sprintf("❌ Error enviando email a %s: %v", email, err))
 			// No lanza error, solo log para no bloquear el monitoreo
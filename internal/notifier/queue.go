@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+)
+
+// queueSize es cuántas notificaciones pueden esperar envío. Si la cola se
+// llena se descartan las nuevas en lugar de bloquear los checks.
+const queueSize = 1000
+
+// StuckAfter es cuánto puede tardar un envío antes de considerar la cola
+// trabada.
+var StuckAfter = 2 * time.Minute
+
+// QueueStats es una foto del estado de la cola de envíos.
+type QueueStats struct {
+	Pending        int     `json:"pending"`
+	Sent           uint64  `json:"sent"`
+	Dropped        uint64  `json:"dropped"`
+	SendingSeconds float64 `json:"sendingSeconds"` // duración del envío en curso, 0 si no hay
+	Stuck          bool    `json:"stuck"`
+}
+
+type message struct {
+	service models.Microservice
+	subject string
+	body    string
+}
+
+var (
+	queue     = make(chan message, queueSize)
+	queueOnce sync.Once
+
+	statsMu      sync.Mutex
+	sent         uint64
+	dropped      uint64
+	sendingSince time.Time
+)
+
+// enqueue deja el mensaje para que lo envíe el worker de la cola.
+func enqueue(service *models.Microservice, subject, body string) {
+	queueOnce.Do(func() { go worker() })
+
+	select {
+	case queue <- message{service: service.Clone(), subject: subject, body: body}:
+	default:
+		statsMu.Lock()
+		dropped++
+		statsMu.Unlock()
+		utils.LogError("❌ Cola de notificaciones llena, se descarta: " + subject)
+	}
+}
+
+func worker() {
+	for msg := range queue {
+		statsMu.Lock()
+		sendingSince = time.Now()
+		statsMu.Unlock()
+
+		sendNotification(&msg.service, msg.subject, msg.body)
+
+		statsMu.Lock()
+		sendingSince = time.Time{}
+		sent++
+		statsMu.Unlock()
+	}
+}
+
+// Stats devuelve el estado de la cola de envíos.
+func Stats() QueueStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stats := QueueStats{Pending: len(queue), Sent: sent, Dropped: dropped}
+	if !sendingSince.IsZero() {
+		stats.SendingSeconds = time.Since(sendingSince).Seconds()
+		stats.Stuck = time.Since(sendingSince) > StuckAfter
+	}
+	return stats
+}
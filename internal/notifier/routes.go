@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"sync"
+
+	"health-check-app-micro/internal/models"
+)
+
+// Route envía las alertas de los servicios con un tag a destinatarios
+// adicionales, por ejemplo todo "team:perfil" al correo del equipo. Solo
+// aplica a los servicios de su tenant.
+type Route struct {
+	Tenant string   `json:"tenant,omitempty"`
+	Tag    string   `json:"tag"`
+	Emails []string `json:"emails"`
+}
+
+var (
+	routesMu sync.RWMutex
+	routes   []Route
+)
+
+// SetRoutes reemplaza las reglas de ruteo por tag.
+func SetRoutes(r []Route) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes = append([]Route(nil), r...)
+}
+
+// Recipients devuelve los destinatarios de las alertas del servicio: sus
+// propios emails más los de cada ruta de su tenant cuyo tag tenga, sin
+// repetidos.
+func Recipients(service *models.Microservice) []string {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+
+	seen := map[string]bool{}
+	var out []string
+	add := func(emails []string) {
+		for _, e := range emails {
+			if e != "" && !seen[e] {
+				seen[e] = true
+				out = append(out, e)
+			}
+		}
+	}
+	add(service.Emails)
+	for _, r := range routes {
+		if models.NormalizeTenant(r.Tenant) == service.Tenant && service.HasTag(r.Tag) {
+			add(r.Emails)
+		}
+	}
+	return out
+}
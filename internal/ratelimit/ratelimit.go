@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval es cada cuánto se descartan los buckets de clientes
+// inactivos, para que la memoria no crezca con cada IP distinta.
+const sweepInterval = time.Minute
+
+// Limiter es un rate limiter token bucket por cliente: cada clave tiene un
+// bucket de hasta burst tokens que se repone a rate tokens por segundo, y
+// cada petición consume uno.
+type Limiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New crea un limiter de rate peticiones por segundo con ráfagas de hasta
+// burst peticiones. rate debe ser positivo; un burst menor que 1 se toma
+// como 1.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// Allow consume un token del cliente key. Si no quedan devuelve false y
+// cuánto falta para que haya uno disponible.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweepLocked(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweepLocked descarta los buckets que ya se habrían llenado: equivalen a
+// un cliente nuevo. Caller MUST hold l.mu.
+func (l *Limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
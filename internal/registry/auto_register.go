@@ -1,118 +1,234 @@
 package registry
 
 import (
+	"bytes"
 	"encoding/json"
-	"health-check-app-micro/internal/checker"
+	"errors"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/graph"
+	"health-check-app-micro/internal/maintenance"
 	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/notifier"
 	"health-check-app-micro/internal/store"
 	"health-check-app-micro/pkg/utils"
-	"os"
-	"time"
+	"io/fs"
+	"strconv"
 )
 
 // ServiceConfig representa la configuración de un servicio para registro automático
 type ServiceConfig struct {
 	Name      string   `json:"name"`
+	Tenant    string   `json:"tenant,omitempty"`
 	Endpoint  string   `json:"endpoint"`
 	Frequency int      `json:"frequency"`
 	Emails    []string `json:"emails"`
+	Tags      []string `json:"tags,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Critical  bool     `json:"critical,omitempty"`
 }
 
+// FileConfig es el contenido del archivo de configuración. El archivo puede
+// ser directamente la lista de servicios o un objeto con esta forma, cuyo
+// bloque defaults se aplica a todos los servicios.
+type FileConfig struct {
+	Defaults           *ServiceDefaults           `json:"defaults,omitempty"`
+	Services           []ServiceConfig            `json:"services"`
+	Maintenance        []models.MaintenanceWindow `json:"maintenance,omitempty"`
+	NotificationRoutes []notifier.Route           `json:"notificationRoutes,omitempty"`
+	APIKeys            []auth.ConfigKey           `json:"apiKeys,omitempty"`
+}
+
+// ParseConfig acepta tanto la lista de servicios como el objeto completo,
+// en JSON y sin resolver referencias ${...}.
+func ParseConfig(data []byte) (*FileConfig, error) {
+	var cfg FileConfig
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &cfg.Services); err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Defaults != nil {
+		for i := range cfg.Services {
+			cfg.Defaults.apply(&cfg.Services[i])
+		}
+	}
+	return &cfg, nil
+}
+
+// StrictConfig hace que AutoRegisterServices falle si el archivo de
+// configuración no existe o tiene cualquier problema, en lugar de cargar lo
+// que pueda o los servicios por defecto. Se configura al arrancar.
+var StrictConfig bool
+
 // AutoRegisterServices registra automáticamente los servicios definidos en el archivo de configuración
-func AutoRegisterServices(storage *store.Store, configPath string) error {
+func AutoRegisterServices(storage store.Storage, configPath string) error {
 	// Si no hay archivo de configuración, usar servicios por defecto
 	if configPath == "" {
-		configPath = "services-config.json"
+		configPath = DefaultConfigPath
 	}
 
 	// Intentar leer el archivo de configuración
-	configData, err := os.ReadFile(configPath)
-	if err != nil {
+	cfg, err := LoadConfigFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if StrictConfig {
+			return errors.New("no se encontró el archivo de configuración " + configPath)
+		}
 		// Si no existe el archivo, usar configuración por defecto
-		utils.LogInfo("⚠️ No se encontró archivo de configuración, usando servicios por defecto")
+		utils.LogWarn("⚠️ No se encontró archivo de configuración, usando servicios por defecto")
 		return registerDefaultServices(storage)
 	}
-
-	var services []ServiceConfig
-	if err := json.Unmarshal(configData, &services); err != nil {
-		utils.LogError("❌ Error parseando archivo de configuración: " + err.Error())
+	if StrictConfig && err != nil {
+		return err
+	}
+	var invalid *ConfigError
+	if errors.As(err, &invalid) {
+		for _, p := range invalid.Problems {
+			utils.LogError("❌ " + p.String())
+		}
+	}
+	if cfg == nil {
+		if invalid == nil {
+			utils.LogError("❌ Error leyendo archivo de configuración: " + err.Error())
+		}
+		utils.LogError("❌ No se pudo interpretar la configuración, usando servicios por defecto")
 		return registerDefaultServices(storage)
 	}
-
-	// Registrar cada servicio
-	for _, svcConfig := range services {
-		service := models.Microservice{
-			Name:      svcConfig.Name,
-			Endpoint:  svcConfig.Endpoint,
-			Frequency: svcConfig.Frequency,
-			Emails:    svcConfig.Emails,
-			Status:    "UNKNOWN",
-			LastCheck: time.Now().Format(time.RFC3339),
+	if invalid != nil {
+		// Se registra lo válido: los servicios señalados no, para que se
+		// cumpla lo que dicen los mensajes (en un repetido, vale el primero)
+		skip := problemServices(invalid.Problems)
+		valid := make([]ServiceConfig, 0, len(cfg.Services))
+		for i, svcConfig := range cfg.Services {
+			if skip[i] {
+				utils.LogWarn("⚠️ Se omite el servicio services[" + strconv.Itoa(i) + "] " + svcConfig.Name + " por los problemas informados")
+				continue
+			}
+			valid = append(valid, svcConfig)
 		}
+		cfg.Services = valid
+	}
 
-		// Validar frecuencia mínima
-		if service.Frequency < 10 {
-			service.Frequency = 30
-		}
+	applyConfig(storage, cfg)
+	return nil
+}
 
-		storage.RegisterService(service)
-		checker.RegisterNewService(storage, &service)
-		utils.LogInfo("✅ Servicio auto-registrado: " + service.Name)
+// applyConfig aplica el archivo de configuración: ventanas, API keys, rutas
+// de notificación y servicios.
+func applyConfig(storage store.Storage, cfg *FileConfig) {
+	windows := configWindows(storage)
+	if err := maintenance.SyncConfig(storage, cfg.Maintenance); err != nil {
+		utils.LogError("❌ Error en ventanas de mantenimiento: " + err.Error())
 	}
+	recordConfigChanges(storage, windowActions, windows, configWindows(storage))
 
-	return nil
+	keys := configKeys(storage)
+	if err := auth.SyncConfig(storage, cfg.APIKeys); err != nil {
+		utils.LogError("❌ Error en API keys: " + err.Error())
+	}
+	recordConfigChanges(storage, keyActions, keys, configKeys(storage))
+	notifier.SetRoutes(cfg.NotificationRoutes)
+	syncConfigServices(storage, cfg.Services, true)
 }
 
-// registerDefaultServices registra los servicios por defecto del sistema
-func registerDefaultServices(storage *store.Store) error {
-	defaultServices := []ServiceConfig{
-		{
-			Name:      "api-gateway",
-			Endpoint:  "http://api-gateway:8085/actuator/health",
-			Frequency: 30,
-			Emails:    []string{os.Getenv("SMTP_TO")},
-		},
-		{
-			Name:      "gestion-perfil",
-			Endpoint:  "http://gestion-perfil:8084/actuator/health",
-			Frequency: 30,
-			Emails:    []string{os.Getenv("SMTP_TO")},
-		},
-		{
-			Name:      "jwt-service",
-			Endpoint:  "http://jwt-service:8081/v1/health",
-			Frequency: 30,
-			Emails:    []string{os.Getenv("SMTP_TO")},
-		},
-		{
-			Name:      "notifications-service",
-			Endpoint:  "http://notifications-service-micro:8080/health",
-			Frequency: 30,
-			Emails:    []string{os.Getenv("SMTP_TO")},
-		},
-		{
-			Name:      "orquestador-solicitudes",
-			Endpoint:  "http://orquestador-solicitudes-micro:3001/health",
-			Frequency: 30,
-			Emails:    []string{os.Getenv("SMTP_TO")},
-		},
+// syncConfigServices registra los servicios del archivo en sus tenants. Las
+// entradas inválidas se descartan con un error en el log para no perder el
+// resto, y los ciclos de dependencias se rompen en lugar de rechazarse.
+// Con prune se eliminan los servicios que venían del archivo y ya no
+// están; los registrados vía API se conservan siempre.
+func syncConfigServices(storage store.Storage, services []ServiceConfig, prune bool) {
+	valid := make([]ServiceConfig, 0, len(services))
+	seen := make(map[string]bool, len(services))
+	for _, svcConfig := range services {
+		if err := svcConfig.Validate(); err != nil {
+			utils.LogError("❌ Servicio inválido en la configuración: " + err.Error())
+			continue
+		}
+		// Si un servicio se repite vale la primera definición, la que
+		// señala el mensaje del validador
+		key := models.QualifiedName(svcConfig.Tenant, svcConfig.Name)
+		if _, ok := seen[key]; ok {
+			utils.LogWarn("⚠️ Servicio repetido en la configuración, se ignora: " + key)
+			continue
+		}
+		seen[key] = true
+		valid = append(valid, svcConfig)
 	}
+	breakDependencyCycles(valid)
 
-	for _, svcConfig := range defaultServices {
-		service := models.Microservice{
-			Name:      svcConfig.Name,
-			Endpoint:  svcConfig.Endpoint,
-			Frequency: svcConfig.Frequency,
-			Emails:    svcConfig.Emails,
-			Status:    "UNKNOWN",
-			LastCheck: time.Now().Format(time.RFC3339),
+	byTenant := make(map[string][]ServiceConfig)
+	if prune {
+		for _, tenant := range store.Tenants(storage) {
+			byTenant[tenant] = nil
+		}
+	}
+	for _, svcConfig := range valid {
+		tenant := models.NormalizeTenant(svcConfig.Tenant)
+		byTenant[tenant] = append(byTenant[tenant], svcConfig)
+	}
+	for tenant, list := range byTenant {
+		opts := SyncOptions{Prune: prune, PruneSource: SourceConfig, Source: SourceConfig, Actor: models.AuditActorConfig}
+		if _, err := Reconcile(store.ForTenant(storage, tenant), list, opts); err != nil {
+			utils.LogError("❌ Error registrando servicios de la configuración: " + err.Error())
 		}
+	}
+}
 
-		storage.RegisterService(service)
-		checker.RegisterNewService(storage, &service)
-		utils.LogInfo("✅ Servicio por defecto registrado: " + service.Name)
+// breakDependencyCycles descarta dependsOn en los servicios que forman un
+// ciclo: con un ciclo cada servicio atribuiría su caída al otro y nadie
+// recibiría la alerta. Las dependencias se resuelven dentro del tenant.
+func breakDependencyCycles(services []ServiceConfig) {
+	for {
+		deps := make(map[string][]string, len(services))
+		for _, s := range services {
+			for _, d := range s.DependsOn {
+				key := models.QualifiedName(s.Tenant, s.Name)
+				deps[key] = append(deps[key], models.QualifiedName(s.Tenant, d))
+			}
+		}
+		cycle := graph.FindCycle(deps)
+		if cycle == nil {
+			return
+		}
+		utils.LogError("❌ " + graph.CycleError(cycle).Error() + "; se ignoran sus dependencias")
+		for i := range services {
+			for _, name := range cycle {
+				if models.QualifiedName(services[i].Tenant, services[i].Name) == name {
+					services[i].DependsOn = nil
+				}
+			}
+		}
 	}
+}
+
+// defaultConfig son los servicios por defecto del sistema, con el mismo
+// formato e interpolación que el archivo de configuración.
+const defaultConfig = `
+defaults:
+  emails: ["${SMTP_TO:-}"]
+services:
+  - name: api-gateway
+    endpoint: http://api-gateway:8085/actuator/health
+  - name: gestion-perfil
+    endpoint: http://gestion-perfil:8084/actuator/health
+  - name: jwt-service
+    endpoint: http://jwt-service:8081/v1/health
+  - name: notifications-service
+    endpoint: http://notifications-service-micro:8080/health
+  - name: orquestador-solicitudes
+    endpoint: http://orquestador-solicitudes-micro:3001/health
+`
 
+// registerDefaultServices registra los servicios por defecto del sistema
+func registerDefaultServices(storage store.Storage) error {
+	// Solo falla si no se puede interpretar; el resto no impide registrarlos
+	cfg, err := parseConfigFile("defaults.yaml", []byte(defaultConfig))
+	if cfg == nil {
+		return err
+	}
+	syncConfigServices(storage, cfg.Services, false)
 	return nil
 }
-
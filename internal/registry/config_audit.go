@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"sort"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/maintenance"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// configObject es una ventana o API key del archivo tal como se audita, con
+// el tenant al que pertenece.
+type configObject struct {
+	tenant string
+	value  any
+}
+
+// configWindows devuelve las ventanas del archivo de configuración por ID.
+func configWindows(storage store.Storage) map[string]configObject {
+	out := map[string]configObject{}
+	for _, w := range storage.ListMaintenance() {
+		if w.Source == maintenance.SourceConfig {
+			out[w.ID] = configObject{tenant: w.Tenant, value: w}
+		}
+	}
+	return out
+}
+
+// configKeys devuelve las API keys del archivo de configuración por ID.
+func configKeys(storage store.Storage) map[string]configObject {
+	out := map[string]configObject{}
+	for _, k := range storage.ListAPIKeys() {
+		if k.Source == auth.SourceConfig {
+			out[k.ID] = configObject{tenant: k.Tenant, value: audit.APIKey(k)}
+		}
+	}
+	return out
+}
+
+// configActions son las acciones de auditoría de un tipo de objeto.
+type configActions struct {
+	kind, create, update, delete string
+}
+
+var (
+	windowActions = configActions{audit.TargetMaintenance, models.AuditMaintenanceCreate, models.AuditMaintenanceUpdate, models.AuditMaintenanceDelete}
+	keyActions    = configActions{audit.TargetAPIKey, models.AuditAPIKeyCreate, models.AuditAPIKeyUpdate, models.AuditAPIKeyDelete}
+)
+
+// recordConfigChanges audita, con el actor config, los objetos que el
+// archivo creó, cambió o quitó. Los que quedaron iguales no se registran.
+func recordConfigChanges(storage store.Storage, actions configActions, before, after map[string]configObject) {
+	ids := make([]string, 0, len(before)+len(after))
+	for id := range after {
+		ids = append(ids, id)
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		entry := models.AuditEntry{Actor: models.AuditActorConfig, Target: audit.Target(actions.kind, id)}
+		b, existed := before[id]
+		a, exists := after[id]
+		switch {
+		case !existed:
+			entry.Action = actions.create
+			audit.Record(store.ForTenant(storage, a.tenant), entry, nil, a.value)
+		case !exists:
+			entry.Action = actions.delete
+			audit.Record(store.ForTenant(storage, b.tenant), entry, b.value, nil)
+		default:
+			entry.Action = actions.update
+			audit.Record(store.ForTenant(storage, a.tenant), entry, b.value, a.value)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceDefaults son los valores del bloque defaults del archivo, que se
+// aplican a cada servicio que no los defina. Los tags se suman a los del
+// servicio.
+type ServiceDefaults struct {
+	Tenant    string   `json:"tenant,omitempty"`
+	Frequency int      `json:"frequency,omitempty"`
+	Emails    []string `json:"emails,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// apply completa el servicio con los valores por defecto.
+func (d ServiceDefaults) apply(s *ServiceConfig) {
+	if s.Tenant == "" {
+		s.Tenant = d.Tenant
+	}
+	if s.Frequency == 0 {
+		s.Frequency = d.Frequency
+	}
+	if len(s.Emails) == 0 {
+		s.Emails = append([]string(nil), d.Emails...)
+	}
+	for _, tag := range d.Tags {
+		if !containsString(s.Tags, tag) {
+			s.Tags = append(s.Tags, tag)
+		}
+	}
+}
+
+// LoadConfigFile lee el archivo de configuración en el formato que indique
+// su extensión (.yaml, .yml, .toml o JSON en cualquier otro caso) y resuelve
+// las referencias ${...} de sus valores.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigFile(path, data)
+}
+
+// parseConfigFile es LoadConfigFile con el contenido ya leído. Junta todos
+// los problemas del archivo en un *ConfigError, con su línea. Si el archivo
+// se pudo interpretar devuelve también la configuración, aunque tenga
+// problemas, para que el arranque no estricto use las entradas válidas.
+func parseConfigFile(path string, data []byte) (*FileConfig, error) {
+	var tree any
+	var err error
+	format := strings.ToLower(filepath.Ext(path))
+	switch format {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		err = json.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return nil, &ConfigError{Problems: []Problem{syntaxProblem(path, data, err)}}
+	}
+
+	root := ""
+	if list, ok := tree.([]any); ok {
+		// La lista sola equivale a {"services": [...]}
+		root = "services"
+		tree = map[string]any{"services": list}
+	}
+	v := &validator{file: path, lines: lineIndex(format, data, root)}
+	tree = v.interpolate(tree, reflect.TypeOf(FileConfig{}), "")
+	v.checkSchema(tree, reflect.TypeOf(FileConfig{}), "")
+	if len(v.problems) > 0 && v.fatal {
+		return nil, v.err()
+	}
+
+	// Todos los formatos se decodifican como JSON para compartir los tags
+	// de los tipos de configuración
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, &ConfigError{Problems: []Problem{{File: path, Message: err.Error()}}}
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, &ConfigError{Problems: []Problem{{File: path, Message: err.Error()}}}
+	}
+	v.checkConfig(cfg)
+	return cfg, v.err()
+}
+
+// interpolate reemplaza las referencias en los textos del árbol:
+//
+//	${VAR}          variable de entorno, que debe estar definida
+//	${VAR:-valor}   variable de entorno o valor si no está definida o es vacía
+//	${file:RUTA}    contenido del archivo, sin el salto de línea final (secretos)
+//	$$              un $ literal
+//
+// Si el texto es solo una referencia y el campo de destino es numérico o
+// booleano, el valor se convierte a ese tipo, así
+// "frequency: ${CHECK_FREQUENCY:-30}" funciona en YAML. En los campos de
+// texto el valor queda como texto aunque parezca un número. t es el tipo del
+// destino según el esquema, o nil si el campo no existe.
+func (v *validator) interpolate(value any, t reflect.Type, path string) any {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch value := value.(type) {
+	case string:
+		out, err := expandValue(value, t)
+		if err != nil {
+			v.fatalf(path, "%v", err)
+			return value
+		}
+		return out
+	case []any:
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for i := range value {
+			value[i] = v.interpolate(value[i], elem, indexPath(path, i))
+		}
+		return value
+	case map[string]any:
+		var known map[string]reflect.Type
+		if t != nil && t.Kind() == reflect.Struct {
+			known = jsonFields(t)
+		}
+		for k := range value {
+			value[k] = v.interpolate(value[k], known[k], fieldPath(path, k))
+		}
+		return value
+	}
+	return value
+}
+
+func expandValue(s string, t reflect.Type) (any, error) {
+	out, err := expand(s)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || !strings.HasPrefix(s, "${") || strings.Index(s, "}") != len(s)-1 {
+		return out, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return out, nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		if n, err := strconv.ParseFloat(out, 64); err == nil {
+			return n, nil
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(out); err == nil {
+			return b, nil
+		}
+	}
+	return out, nil
+}
+
+func expand(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] != '$':
+			b.WriteByte(s[i])
+		case strings.HasPrefix(s[i:], "$$"):
+			b.WriteByte('$')
+			i++
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("falta } en %q", s)
+			}
+			value, err := resolve(s[i+2 : i+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve devuelve el valor de una referencia sin ${ }.
+func resolve(ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secreto %s: %w", path, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", fmt.Errorf("referencia vacía ${%s}", ref)
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if hasDefault {
+		return def, nil
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return "", nil
+	}
+	return "", fmt.Errorf("variable %s no definida", name)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// lineIndex devuelve la línea donde empieza cada valor del archivo, por su
+// ruta (p. ej. services[2].endpoint). root es la ruta del documento: vacía,
+// o "services" si el archivo es solo la lista. Si el archivo no se puede
+// recorrer el índice queda incompleto y los problemas salen sin línea.
+func lineIndex(format string, data []byte, root string) map[string]int {
+	lines := make(map[string]int)
+	switch format {
+	case ".yaml", ".yml":
+		var doc yaml.Node
+		if yaml.Unmarshal(data, &doc) == nil && len(doc.Content) > 0 {
+			yamlLines(doc.Content[0], root, lines)
+		}
+	case ".toml":
+		tomlLines(data, lines)
+	default:
+		jsonLines(data, root, lines)
+	}
+	return lines
+}
+
+func lineAt(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	return bytes.Count(data[:offset], []byte{'\n'}) + 1
+}
+
+func yamlLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := fieldPath(path, key.Value)
+			lines[child] = key.Line
+			yamlLines(value, child, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := indexPath(path, i)
+			lines[child] = item.Line
+			yamlLines(item, child, lines)
+		}
+	}
+}
+
+// jsonLines recorre los tokens del documento llevando la ruta de cada valor.
+func jsonLines(data []byte, root string, lines map[string]int) {
+	type frame struct {
+		path   string
+		object bool
+		key    string // clave del próximo valor, en objetos
+		index  int    // índice del próximo valor, en listas
+		onKey  bool   // en objetos, si el próximo token es una clave
+	}
+	var stack []*frame
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.onKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		// El token empieza después de los espacios y separadores
+		start := int(dec.InputOffset())
+		for start < len(data) && strings.IndexByte(" \t\r\n,:", data[start]) >= 0 {
+			start++
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			valueDone()
+			continue
+		}
+
+		path := root
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.object && top.onKey {
+				top.key, _ = tok.(string)
+				top.onKey = false
+				continue
+			}
+			if top.object {
+				path = fieldPath(top.path, top.key)
+			} else {
+				path = indexPath(top.path, top.index)
+			}
+		}
+		if path != "" {
+			lines[path] = lineAt(data, start)
+		}
+		if isDelim {
+			stack = append(stack, &frame{path: path, object: delim == '{', onKey: delim == '{'})
+			continue
+		}
+		valueDone()
+	}
+}
+
+// tomlLines recorre las expresiones del documento: tablas, listas de tablas
+// ([[services]]) y pares clave = valor.
+func tomlLines(data []byte, lines map[string]int) {
+	var p unstable.Parser
+	p.Reset(data)
+	arrays := make(map[string]int) // elementos de cada lista de tablas
+	table := ""
+
+	// resolve arma la ruta de una clave con puntos. Los segmentos que son
+	// listas de tablas apuntan a su último elemento, salvo el final de un
+	// [[encabezado]], que agrega uno nuevo.
+	resolve := func(base string, it unstable.Iterator, arrayTable bool) (string, int) {
+		var keys []*unstable.Node
+		for it.Next() {
+			keys = append(keys, it.Node())
+		}
+		path, line := base, 0
+		for i, key := range keys {
+			if line == 0 {
+				line = p.Shape(key.Raw).Start.Line
+			}
+			path = fieldPath(path, string(key.Data))
+			if arrayTable && i == len(keys)-1 {
+				n := arrays[path]
+				arrays[path] = n + 1
+				path = indexPath(path, n)
+				break
+			}
+			if n, ok := arrays[path]; ok {
+				path = indexPath(path, n-1)
+			}
+		}
+		return path, line
+	}
+
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			path, line := resolve("", expr.Key(), expr.Kind == unstable.ArrayTable)
+			table = path
+			lines[path] = line
+		case unstable.KeyValue:
+			path, line := resolve(table, expr.Key(), false)
+			lines[path] = line
+			tomlValueLines(&p, expr.Value(), path, lines)
+		}
+	}
+}
+
+func tomlValueLines(p *unstable.Parser, value *unstable.Node, path string, lines map[string]int) {
+	switch value.Kind {
+	case unstable.Array:
+		it := value.Children()
+		for i := 0; it.Next(); i++ {
+			child := indexPath(path, i)
+			if item := it.Node(); item.Raw.Length > 0 {
+				lines[child] = p.Shape(item.Raw).Start.Line
+			}
+			tomlValueLines(p, it.Node(), child, lines)
+		}
+	case unstable.InlineTable:
+		it := value.Children()
+		for it.Next() {
+			kv := it.Node()
+			keys := kv.Key()
+			child := path
+			line := 0
+			for keys.Next() {
+				key := keys.Node()
+				if line == 0 {
+					line = p.Shape(key.Raw).Start.Line
+				}
+				child = fieldPath(child, string(key.Data))
+			}
+			lines[child] = line
+			tomlValueLines(p, kv.Value(), child, lines)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/maintenance"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+)
+
+// DefaultConfigPath es el archivo de configuración si no se indica otro.
+const DefaultConfigPath = "services-config.json"
+
+// validateConfig comprueba todo el archivo sin cambiar nada: los servicios
+// se validan con una sincronización en seco por tenant.
+func validateConfig(storage store.Storage, cfg *FileConfig) error {
+	if err := maintenance.ValidateConfig(cfg.Maintenance); err != nil {
+		return err
+	}
+	if err := auth.ValidateConfig(cfg.APIKeys); err != nil {
+		return err
+	}
+	byTenant := make(map[string][]ServiceConfig)
+	for _, svcConfig := range cfg.Services {
+		if !models.ValidTenant(svcConfig.Tenant) {
+			return &ValidationError{Problems: []string{svcConfig.Name + ": tenant inválido " + svcConfig.Tenant}}
+		}
+		tenant := models.NormalizeTenant(svcConfig.Tenant)
+		byTenant[tenant] = append(byTenant[tenant], svcConfig)
+	}
+	for tenant, list := range byTenant {
+		opts := SyncOptions{Prune: true, PruneSource: SourceConfig, Source: SourceConfig, DryRun: true}
+		if _, err := Reconcile(store.ForTenant(storage, tenant), list, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigWatcher recarga el archivo de configuración cuando cambia su
+// contenido (revisándolo cada cierto intervalo) o cuando se le pide con
+// Reload, por ejemplo al recibir SIGHUP. Cada recarga reconcilia el store
+// con el archivo: crea, actualiza y elimina los servicios que vienen de él
+// y reemplaza ventanas, API keys y rutas de notificación. A diferencia del
+// arranque, si algo del archivo es inválido no aplica nada, así los checks
+// siguen con la configuración anterior.
+type ConfigWatcher struct {
+	storage  store.Storage
+	path     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	sum     [sha256.Size]byte
+	readErr bool
+}
+
+// NewConfigWatcher crea el watcher del archivo path y toma su contenido
+// actual como ya aplicado. Conviene crearlo antes de la carga inicial para
+// no perder cambios hechos entre ambas.
+func NewConfigWatcher(storage store.Storage, path string, interval time.Duration) *ConfigWatcher {
+	if path == "" {
+		path = DefaultConfigPath
+	}
+	w := &ConfigWatcher{storage: storage, path: path, interval: interval}
+	if data, err := os.ReadFile(path); err == nil {
+		w.sum = sha256.Sum256(data)
+	}
+	return w
+}
+
+// Run revisa el archivo cada intervalo hasta que se cancele ctx. Con un
+// intervalo no positivo no hace nada y solo quedan las recargas con Reload.
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	if w.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// Reload recarga el archivo aunque no haya cambiado. Devuelve el error si
+// no se pudo leer o es inválido.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		utils.LogError("❌ No se pudo leer la configuración: " + err.Error())
+		return err
+	}
+	return w.apply(data)
+}
+
+// check recarga el archivo si su contenido cambió desde la última vez.
+func (w *ConfigWatcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		// Se avisa una sola vez mientras el archivo no se pueda leer
+		if !w.readErr {
+			utils.LogError("❌ No se pudo leer la configuración: " + err.Error())
+		}
+		w.readErr = true
+		return
+	}
+	w.readErr = false
+	if sha256.Sum256(data) == w.sum {
+		return
+	}
+	_ = w.apply(data)
+}
+
+// apply aplica el contenido y lo recuerda aunque sea inválido, para no
+// repetir el error en cada revisión hasta que el archivo vuelva a cambiar.
+// Caller MUST hold w.mu.
+func (w *ConfigWatcher) apply(data []byte) error {
+	w.sum = sha256.Sum256(data)
+	utils.LogInfo("🔄 Recargando configuración desde " + w.path)
+	cfg, err := parseConfigFile(w.path, data)
+	if err == nil {
+		err = validateConfig(w.storage, cfg)
+	}
+	if err != nil {
+		utils.LogError("❌ Configuración rechazada, se mantiene la anterior: " + err.Error())
+		return err
+	}
+	applyConfig(w.storage, cfg)
+	utils.LogInfo("✅ Configuración recargada: " + strconv.Itoa(len(cfg.Services)) + " servicios")
+	return nil
+}
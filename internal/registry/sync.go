@@ -0,0 +1,337 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/graph"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+	"health-check-app-micro/pkg/utils"
+)
+
+// Origen de un servicio: registrado vía API o desde el archivo de
+// configuración.
+const (
+	SourceAPI    = "api"
+	SourceConfig = "config"
+)
+
+// Acciones de un SyncChange.
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// ErrServiceLimit indica que la sincronización dejaría más servicios que
+// SyncOptions.MaxServices.
+var ErrServiceLimit = errors.New("se supera el máximo de servicios")
+
+// syncMu serializa las sincronizaciones: cada una calcula el plan sobre el
+// estado actual y lo aplica sin que otra se intercale.
+var syncMu sync.Mutex
+
+// SyncOptions controla cómo Reconcile aplica la lista deseada.
+type SyncOptions struct {
+	Prune       bool   // eliminar los servicios que no están en la lista
+	PruneSource string // con Prune, eliminar solo los de este origen; vacío es todos
+	Source      string // origen que se asigna a los servicios de la lista
+	DryRun      bool   // solo calcular los cambios, sin aplicarlos
+	MaxServices int    // máximo de servicios resultantes; 0 es sin límite
+	Actor       string // autor de los cambios en la auditoría
+	SourceIP    string
+}
+
+// SyncChange es el cambio que la sincronización hace (o haría) en un servicio.
+type SyncChange struct {
+	Action  string               `json:"action"`
+	Service string               `json:"service"`
+	Changes []models.AuditChange `json:"changes,omitempty"` // campos modificados, solo en update
+}
+
+// SyncResult resume la sincronización. Changes viene ordenado por servicio.
+type SyncResult struct {
+	DryRun    bool         `json:"dryRun"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Deleted   int          `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+	Changes   []SyncChange `json:"changes"`
+}
+
+// ValidationError agrupa los problemas de una lista de servicios inválida.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "configuración de servicios inválida: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate comprueba los campos de un servicio de la configuración.
+func (s ServiceConfig) Validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("el nombre es requerido")
+	case !models.ValidServiceName(s.Name):
+		return fmt.Errorf("%s: el nombre no puede contener /", s.Name)
+	case !models.ValidTenant(s.Tenant):
+		return fmt.Errorf("%s: tenant inválido %q", s.Name, s.Tenant)
+	case !strings.HasPrefix(s.Endpoint, "http://") && !strings.HasPrefix(s.Endpoint, "https://"):
+		return fmt.Errorf("%s: el endpoint debe comenzar con http:// o https://", s.Name)
+	}
+	for _, dep := range s.DependsOn {
+		if !models.ValidServiceName(dep) {
+			return fmt.Errorf("%s: dependencia inválida %s", s.Name, dep)
+		}
+	}
+	return nil
+}
+
+// Reconcile lleva los servicios de storage (la vista de un tenant) a la
+// lista deseada: crea los nuevos, actualiza los que cambiaron y, con Prune,
+// elimina los que faltan. El campo Tenant de la lista se ignora. Si la
+// lista es inválida no cambia nada y devuelve un *ValidationError.
+//
+// Los cambios se auditan y, una vez guardados todos, se reprograman los
+// checks de los servicios creados o modificados. Los eliminados se
+// desprograman solos. Los servicios sin cambios conservan su estado.
+//
+// Con MaxServices las altas se cuentan en el store de forma atómica; si una
+// alta concurrente ocupa el último lugar, lo aplicado hasta ese momento
+// queda y devuelve ErrServiceLimit. Lo mismo si el store no puede guardar
+// un alta, con su error.
+func Reconcile(storage store.Storage, services []ServiceConfig, opts SyncOptions) (*SyncResult, error) {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+
+	current := storage.GetAll()
+	desired := make(map[string]bool, len(services))
+	for _, cfg := range services {
+		desired[cfg.Name] = true
+	}
+	// kept son los servicios que quedan además de los de la lista
+	kept := make(map[string]*models.Microservice, len(current))
+	var deletes []string
+	for name, service := range current {
+		if desired[name] {
+			continue
+		}
+		if opts.Prune && (opts.PruneSource == "" || service.Source == opts.PruneSource) {
+			deletes = append(deletes, name)
+			continue
+		}
+		kept[name] = service
+	}
+	if err := validateSync(kept, services); err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{DryRun: opts.DryRun, Changes: []SyncChange{}}
+	var writes []models.Microservice
+	for _, cfg := range services {
+		service := cfg.service()
+		service.Source = opts.Source
+		before := current[cfg.Name]
+		if before == nil {
+			result.Created++
+			result.Changes = append(result.Changes, SyncChange{Action: SyncCreate, Service: cfg.Name})
+			writes = append(writes, service)
+			continue
+		}
+		// Editar la configuración no cambia el estado: un servicio caído
+		// sigue caído con su incidente, sin volver a alertar ni quedar
+		// abierto al recuperarse
+		service.Tenant = before.Tenant
+		service.Paused = before.Paused
+		service.Status = before.Status
+		service.LastCheck = before.LastCheck
+		service.LastResult = before.LastResult
+		changes := serviceChanges(before, &service)
+		if len(changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		result.Updated++
+		result.Changes = append(result.Changes, SyncChange{Action: SyncUpdate, Service: cfg.Name, Changes: changes})
+		writes = append(writes, service)
+	}
+	for _, name := range deletes {
+		result.Deleted++
+		result.Changes = append(result.Changes, SyncChange{Action: SyncDelete, Service: name})
+	}
+	sort.Slice(result.Changes, func(i, j int) bool { return result.Changes[i].Service < result.Changes[j].Service })
+
+	if total := len(current) + result.Created - result.Deleted; opts.MaxServices > 0 && result.Created > result.Deleted && total > opts.MaxServices {
+		return nil, ErrServiceLimit
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	entry := func(action, name string) models.AuditEntry {
+		return models.AuditEntry{Actor: opts.Actor, SourceIP: opts.SourceIP, Action: action, Target: audit.Target(audit.TargetService, name)}
+	}
+	// Primero se eliminan, para que los lugares que liberan cuenten en el
+	// máximo de servicios
+	applied := make(map[string]bool, len(deletes)+len(writes))
+	for _, name := range deletes {
+		if storage.DeleteService(name) {
+			audit.Record(storage, entry(models.AuditServiceDelete, name), audit.Service(current[name]), nil)
+		}
+		applied[name] = true
+	}
+	var err error
+	var registered []string
+	for _, service := range writes {
+		before := current[service.Name]
+		action := models.AuditServiceUpdate
+		if before == nil {
+			// El alta se cuenta en el store, atómica con las de POST /register
+			ok, regErr := storage.RegisterServiceLimited(service, opts.MaxServices)
+			if regErr != nil {
+				err = regErr
+				break
+			}
+			if !ok {
+				err = ErrServiceLimit
+				break
+			}
+			action = models.AuditServiceRegister
+		} else {
+			storage.RegisterService(service)
+		}
+		applied[service.Name] = true
+		registered = append(registered, service.Name)
+		audit.Record(storage, entry(action, service.Name), audit.Service(before), audit.Service(storage.Get(service.Name)))
+	}
+
+	// Si otra alta ocupó el último lugar, lo ya aplicado queda y se informa
+	changes := result.Changes
+	if err != nil {
+		changes = nil
+		for _, change := range result.Changes {
+			if applied[change.Service] {
+				changes = append(changes, change)
+			}
+		}
+	}
+	logChanges(storage, current, changes)
+	for _, name := range registered {
+		if service := storage.Get(name); service != nil {
+			checker.RegisterNewService(storage, service)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateSync junta todos los problemas de la lista: campos inválidos,
+// nombres repetidos y ciclos en el grafo de dependencias que formaría con
+// los servicios que se conservan.
+func validateSync(kept map[string]*models.Microservice, services []ServiceConfig) error {
+	var problems []string
+	seen := make(map[string]bool, len(services))
+	for _, cfg := range services {
+		if err := cfg.Validate(); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if seen[cfg.Name] {
+			problems = append(problems, cfg.Name+": servicio repetido")
+		}
+		seen[cfg.Name] = true
+	}
+	if len(problems) == 0 {
+		deps := graph.DependencyMap(kept)
+		for _, cfg := range services {
+			deps[cfg.Name] = cfg.DependsOn
+		}
+		if cycle := graph.FindCycle(deps); cycle != nil {
+			problems = append(problems, graph.CycleError(cycle).Error())
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// service arma el servicio a registrar a partir de la configuración.
+func (s ServiceConfig) service() models.Microservice {
+	service := models.Microservice{
+		Name:      s.Name,
+		Endpoint:  s.Endpoint,
+		Frequency: s.Frequency,
+		Emails:    nonEmpty(s.Emails),
+		Tags:      nonEmpty(s.Tags),
+		DependsOn: nonEmpty(s.DependsOn),
+		Critical:  s.Critical,
+		Status:    "UNKNOWN",
+		LastCheck: time.Now().Format(time.RFC3339),
+	}
+	// Validar frecuencia mínima
+	if service.Frequency < 10 {
+		service.Frequency = models.DefaultFrequency
+	}
+	return service
+}
+
+// nonEmpty normaliza las listas vacías a nil, como quedan guardadas, para
+// que "emails": [] no cuente como un cambio en cada sincronización.
+func nonEmpty(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// serviceChanges compara la configuración auditable de dos versiones del
+// servicio y devuelve los campos que difieren.
+func serviceChanges(before, after *models.Microservice) []models.AuditChange {
+	b, err := json.Marshal(audit.Service(before))
+	if err != nil {
+		return nil
+	}
+	a, err := json.Marshal(audit.Service(after))
+	if err != nil {
+		return nil
+	}
+	return audit.Diff(b, a)
+}
+
+// logChanges deja en el log cada cambio aplicado, con los campos
+// modificados en las actualizaciones. before es el estado previo, para
+// nombrar a los eliminados con su tenant.
+func logChanges(storage store.Storage, before map[string]*models.Microservice, changes []SyncChange) {
+	for _, change := range changes {
+		name := change.Service
+		if service := storage.Get(name); service != nil {
+			name = service.QualifiedName()
+		} else if service := before[name]; service != nil {
+			name = service.QualifiedName()
+		}
+		switch change.Action {
+		case SyncCreate:
+			utils.LogInfo("✅ Servicio creado: " + name)
+		case SyncUpdate:
+			fields := make([]string, len(change.Changes))
+			for i, c := range change.Changes {
+				fields[i] = c.Field
+			}
+			utils.LogInfo("✏️ Servicio actualizado: " + name + " (" + strings.Join(fields, ", ") + ")")
+		case SyncDelete:
+			utils.LogInfo("🗑️ Servicio eliminado: " + name)
+		}
+	}
+}
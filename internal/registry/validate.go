@@ -0,0 +1,349 @@
+package registry
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/graph"
+	"health-check-app-micro/internal/maintenance"
+	"health-check-app-micro/internal/models"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Límites de la frecuencia de un servicio en el archivo de configuración,
+// en segundos. 0 toma la frecuencia por defecto.
+const (
+	MinFrequency = 10
+	MaxFrequency = 24 * 60 * 60
+)
+
+// Problem es un error del archivo de configuración.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Path    string `json:"path,omitempty"` // p. ej. services[2].endpoint
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	loc := p.File
+	if p.Line > 0 {
+		loc += ":" + strconv.Itoa(p.Line)
+	}
+	if p.Path != "" {
+		loc += ": " + p.Path
+	}
+	return loc + ": " + p.Message
+}
+
+// ConfigError reúne todos los problemas de un archivo de configuración.
+type ConfigError struct {
+	Problems []Problem
+}
+
+func (e *ConfigError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return "configuración inválida:\n  " + strings.Join(lines, "\n  ")
+}
+
+// validator junta los problemas de un archivo. Los fatales impiden
+// interpretarlo; el resto invalida solo las entradas afectadas.
+type validator struct {
+	file     string
+	lines    map[string]int
+	problems []Problem
+	fatal    bool
+}
+
+func (v *validator) errorf(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{File: v.file, Line: v.lineOf(path), Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) fatalf(path, format string, args ...any) {
+	v.fatal = true
+	v.errorf(path, format, args...)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: v.problems}
+}
+
+// lineOf devuelve la línea del valor en path o, si no se conoce, la del
+// objeto más cercano que lo contiene.
+func (v *validator) lineOf(path string) int {
+	for path != "" {
+		if line, ok := v.lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// checkSchema compara el árbol del archivo con el tipo que lo va a recibir:
+// informa campos desconocidos (que no impiden cargarlo) y tipos incorrectos.
+func (v *validator) checkSchema(value any, t reflect.Type, path string) {
+	if value == nil {
+		return
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		switch value := value.(type) {
+		case string:
+			if err := reflect.New(t).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+				v.fatalf(path, "valor inválido %q", value)
+			}
+		case time.Time:
+		case toml.LocalDateTime, toml.LocalDate, toml.LocalTime:
+			v.fatalf(path, "la fecha debe incluir la zona horaria")
+		default:
+			v.fatalf(path, "se esperaba un texto")
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			v.fatalf(path, "se esperaba un texto")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			v.fatalf(path, "se esperaba true o false")
+		}
+	case reflect.Int, reflect.Int64:
+		if !isInteger(value) {
+			v.fatalf(path, "se esperaba un número entero")
+		}
+	case reflect.Slice:
+		list, ok := value.([]any)
+		if !ok {
+			v.fatalf(path, "se esperaba una lista")
+			return
+		}
+		for i, item := range list {
+			v.checkSchema(item, t.Elem(), indexPath(path, i))
+		}
+	case reflect.Struct:
+		fields, ok := value.(map[string]any)
+		if !ok {
+			v.fatalf(path, "se esperaba un objeto")
+			return
+		}
+		known := jsonFields(t)
+		for name, item := range fields {
+			field, ok := known[name]
+			if !ok {
+				v.errorf(fieldPath(path, name), "campo desconocido")
+				continue
+			}
+			v.checkSchema(item, field, fieldPath(path, name))
+		}
+	}
+}
+
+func isInteger(value any) bool {
+	switch n := value.(type) {
+	case int, int64:
+		return true
+	case float64:
+		return n == math.Trunc(n)
+	}
+	return false
+}
+
+// jsonFields devuelve los tipos de los campos de un struct por su nombre en
+// JSON.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// checkConfig valida el contenido de la configuración ya decodificada.
+func (v *validator) checkConfig(cfg *FileConfig) {
+	v.checkServices(cfg.Services)
+	for i, w := range cfg.Maintenance {
+		path := indexPath("maintenance", i)
+		if err := maintenance.ValidateConfig([]models.MaintenanceWindow{w}); err != nil {
+			v.errorf(path, "%v", err)
+		}
+	}
+	for i, k := range cfg.APIKeys {
+		if err := auth.ValidateConfig([]auth.ConfigKey{k}); err != nil {
+			v.errorf(indexPath("apiKeys", i), "%v", err)
+		}
+	}
+	for i, r := range cfg.NotificationRoutes {
+		path := indexPath("notificationRoutes", i)
+		if r.Tag == "" {
+			v.errorf(fieldPath(path, "tag"), "el tag es requerido")
+		}
+		if !models.ValidTenant(r.Tenant) {
+			v.errorf(fieldPath(path, "tenant"), "tenant inválido %q", r.Tenant)
+		}
+		v.checkEmails(r.Emails, fieldPath(path, "emails"))
+	}
+}
+
+func (v *validator) checkServices(services []ServiceConfig) {
+	seen := make(map[string]string, len(services))
+	deps := make(map[string][]string)
+	for i, s := range services {
+		path := indexPath("services", i)
+		switch {
+		case s.Name == "":
+			v.errorf(fieldPath(path, "name"), "el nombre es requerido")
+		case !models.ValidServiceName(s.Name):
+			v.errorf(fieldPath(path, "name"), "el nombre no puede contener /")
+		}
+		if !models.ValidTenant(s.Tenant) {
+			v.errorf(fieldPath(path, "tenant"), "tenant inválido %q", s.Tenant)
+		}
+		if err := checkEndpoint(s.Endpoint); err != nil {
+			v.errorf(fieldPath(path, "endpoint"), "%v", err)
+		}
+		if s.Frequency != 0 && (s.Frequency < MinFrequency || s.Frequency > MaxFrequency) {
+			v.errorf(fieldPath(path, "frequency"), "la frecuencia debe estar entre %d y %d segundos", MinFrequency, MaxFrequency)
+		}
+		v.checkEmails(s.Emails, fieldPath(path, "emails"))
+		for j, dep := range s.DependsOn {
+			switch {
+			case !models.ValidServiceName(dep):
+				v.errorf(indexPath(fieldPath(path, "dependsOn"), j), "dependencia inválida %q", dep)
+			case dep == s.Name:
+				v.errorf(indexPath(fieldPath(path, "dependsOn"), j), "un servicio no puede depender de sí mismo")
+			}
+		}
+
+		if s.Name == "" {
+			continue
+		}
+		key := models.QualifiedName(models.NormalizeTenant(s.Tenant), s.Name)
+		if first, ok := seen[key]; ok {
+			msg := "servicio repetido, ya definido en " + first
+			if line := v.lineOf(first); line > 0 {
+				msg += " (línea " + strconv.Itoa(line) + ")"
+			}
+			v.errorf(fieldPath(path, "name"), "%s", msg)
+			continue
+		}
+		seen[key] = path
+		for _, dep := range s.DependsOn {
+			deps[key] = append(deps[key], models.QualifiedName(models.NormalizeTenant(s.Tenant), dep))
+		}
+	}
+	if cycle := graph.FindCycle(deps); cycle != nil {
+		v.errorf(fieldPath(seen[cycle[0]], "dependsOn"), "%v", graph.CycleError(cycle))
+	}
+}
+
+func checkEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return errors.New("el endpoint es requerido")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("URL inválida %q: debe ser http:// o https:// con host", endpoint)
+	}
+	return nil
+}
+
+// checkEmails informa las direcciones mal formadas. Las vacías se aceptan:
+// son las que quedan de ${VAR:-} sin definir y no reciben alertas.
+func (v *validator) checkEmails(emails []string, path string) {
+	for i, e := range emails {
+		if e == "" {
+			continue
+		}
+		if addr, err := mail.ParseAddress(e); err != nil || addr.Address != e {
+			v.errorf(indexPath(path, i), "email inválido %q", e)
+		}
+	}
+}
+
+// syntaxProblem convierte el error de sintaxis del formato en un problema
+// con su línea.
+func syntaxProblem(file string, data []byte, err error) Problem {
+	p := Problem{File: file, Message: err.Error()}
+	var jsonErr *json.SyntaxError
+	var tomlErr *toml.DecodeError
+	switch {
+	case errors.As(err, &jsonErr):
+		p.Line = lineAt(data, int(jsonErr.Offset))
+	case errors.As(err, &tomlErr):
+		p.Line, _ = tomlErr.Position()
+	default:
+		// yaml.v3 incluye la línea en el mensaje: "yaml: line 3: ..."
+		if _, rest, ok := strings.Cut(err.Error(), "line "); ok {
+			p.Line, _ = strconv.Atoi(strings.SplitN(rest, ":", 2)[0])
+		}
+	}
+	return p
+}
+
+// problemServices devuelve los índices de los servicios señalados por algún
+// problema, para no registrarlos cuando se carga lo que se pueda.
+func problemServices(problems []Problem) map[int]bool {
+	out := make(map[int]bool)
+	for _, p := range problems {
+		rest, ok := strings.CutPrefix(p.Path, "services[")
+		if !ok {
+			continue
+		}
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			continue
+		}
+		if i, err := strconv.Atoi(rest[:end]); err == nil {
+			out[i] = true
+		}
+	}
+	return out
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"sort"
+
+	"health-check-app-micro/internal/models"
+)
+
+// Estados agregados de un grupo de servicios.
+const (
+	StatusUp       = "UP"
+	StatusDegraded = "DEGRADED"
+	StatusDown     = "DOWN"
+	StatusUnknown  = "UNKNOWN"
+)
+
+// Group resume el estado de los servicios que comparten el valor de un tag,
+// por ejemplo todos los de "team:perfil".
+type Group struct {
+	Key         string   `json:"key"`
+	Value       string   `json:"value"`
+	Status      string   `json:"status"`
+	Total       int      `json:"total"`
+	Up          int      `json:"up"`
+	Down        int      `json:"down"`
+	Maintenance int      `json:"maintenance"`
+	Unknown     int      `json:"unknown"`
+	Services    []string `json:"services"`
+}
+
+// GroupBy agrupa los servicios por el valor del tag key. Los servicios sin
+// ese tag no aparecen en ningún grupo.
+func GroupBy(services map[string]*models.Microservice, key string) []Group {
+	byValue := map[string]*Group{}
+	for _, s := range services {
+		value, ok := s.TagValue(key)
+		if !ok {
+			continue
+		}
+		g := byValue[value]
+		if g == nil {
+			g = &Group{Key: key, Value: value, Services: []string{}}
+			byValue[value] = g
+		}
+		g.add(s)
+	}
+
+	groups := make([]Group, 0, len(byValue))
+	for _, g := range byValue {
+		sort.Strings(g.Services)
+		g.Status = g.status()
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Value < groups[j].Value })
+	return groups
+}
+
+func (g *Group) add(s *models.Microservice) {
+	g.Total++
+	g.Services = append(g.Services, s.Name)
+	switch s.Status {
+	case "UP":
+		g.Up++
+	case "DOWN":
+		g.Down++
+	case models.StatusMaintenance:
+		g.Maintenance++
+	default:
+		g.Unknown++
+	}
+}
+
+// status agrega el estado del grupo: DOWN si todos los servicios verificados
+// están caídos, DEGRADED si solo algunos, UP si ninguno. Los servicios en
+// mantenimiento cuentan como disponibles.
+func (g *Group) status() string {
+	checked := g.Up + g.Down + g.Maintenance
+	switch {
+	case checked == 0:
+		return StatusUnknown
+	case g.Down == 0:
+		return StatusUp
+	case g.Down == checked:
+		return StatusDown
+	default:
+		return StatusDegraded
+	}
+}
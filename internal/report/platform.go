@@ -0,0 +1,87 @@
+package report
+
+import (
+	"sort"
+
+	"health-check-app-micro/internal/models"
+)
+
+// Platform es el estado agregado de todos los servicios monitoreados:
+// DOWN si cae algún servicio crítico, DEGRADED si solo caen no críticos y
+// UP en otro caso. Mantenimiento y servicios sin verificar no degradan.
+type Platform struct {
+	Status       string   `json:"status"`
+	Services     int      `json:"services"`
+	Critical     int      `json:"critical"`
+	CriticalDown []string `json:"criticalDown"`
+	Down         []string `json:"down"` // no críticos caídos
+	Maintenance  []string `json:"maintenance"`
+	Unknown      []string `json:"unknown"`
+}
+
+// PlatformStatus calcula el estado de la plataforma.
+func PlatformStatus(services map[string]*models.Microservice) Platform {
+	p := Platform{
+		Services:     len(services),
+		CriticalDown: []string{},
+		Down:         []string{},
+		Maintenance:  []string{},
+		Unknown:      []string{},
+	}
+	for name, s := range services {
+		if s.Critical {
+			p.Critical++
+		}
+		switch s.Status {
+		case "UP":
+		case "DOWN":
+			if s.Critical {
+				p.CriticalDown = append(p.CriticalDown, name)
+			} else {
+				p.Down = append(p.Down, name)
+			}
+		case models.StatusMaintenance:
+			p.Maintenance = append(p.Maintenance, name)
+		default:
+			p.Unknown = append(p.Unknown, name)
+		}
+	}
+	for _, list := range [][]string{p.CriticalDown, p.Down, p.Maintenance, p.Unknown} {
+		sort.Strings(list)
+	}
+
+	switch {
+	case len(p.CriticalDown) > 0:
+		p.Status = StatusDown
+	case len(p.Down) > 0:
+		p.Status = StatusDegraded
+	default:
+		p.Status = StatusUp
+	}
+	return p
+}
+
+// Summary es el estado de la plataforma sin los nombres de los servicios,
+// para quien no puede leerlos.
+type Summary struct {
+	Status       string `json:"status"`
+	Services     int    `json:"services"`
+	Critical     int    `json:"critical"`
+	CriticalDown int    `json:"criticalDown"`
+	Down         int    `json:"down"`
+	Maintenance  int    `json:"maintenance"`
+	Unknown      int    `json:"unknown"`
+}
+
+// Summary devuelve el estado con solo los contadores.
+func (p Platform) Summary() Summary {
+	return Summary{
+		Status:       p.Status,
+		Services:     p.Services,
+		Critical:     p.Critical,
+		CriticalDown: len(p.CriticalDown),
+		Down:         len(p.Down),
+		Maintenance:  len(p.Maintenance),
+		Unknown:      len(p.Unknown),
+	}
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Uptime resume la disponibilidad de un servicio en una ventana de tiempo.
+// Los checks hechos durante mantenimiento se informan aparte y no cuentan
+// para el porcentaje.
+type Uptime struct {
+	Service       string   `json:"service"`
+	Window        string   `json:"window"`
+	Checks        int      `json:"checks"`
+	Up            int      `json:"up"`
+	Down          int      `json:"down"`
+	Maintenance   int      `json:"maintenance"`
+	UptimePercent *float64 `json:"uptimePercent"` // nil si no hubo checks computables
+
+	// Since es el inicio de la ventana y From el del primer dato dentro de
+	// ella: si From es bastante posterior, los datos no cubren la ventana
+	Since time.Time  `json:"since"`
+	From  *time.Time `json:"from,omitempty"`
+}
+
+// WindowUptime calcula el uptime del servicio en la ventana d hasta now.
+// Las ventanas de más de un día se calculan con el resumen diario, que se
+// conserva store.HistoryDays días, contando días UTC completos; las demás,
+// con el historial detallado.
+func WindowUptime(storage store.Storage, name, window string, d time.Duration, now time.Time) Uptime {
+	if d <= 24*time.Hour {
+		since := now.Add(-d)
+		history := storage.History(name, since)
+		u := ComputeUptime(name, window, history)
+		u.Since = since
+		for _, r := range history {
+			if u.From == nil || r.Timestamp.Before(*u.From) {
+				from := r.Timestamp
+				u.From = &from
+			}
+		}
+		return u
+	}
+	days := int((d + 24*time.Hour - 1) / (24 * time.Hour))
+	since := models.Day(now).AddDate(0, 0, -(days - 1))
+	stats := storage.DailyStats(name, since)
+	u := ComputeDailyUptime(name, window, stats)
+	u.Since = since
+	if len(stats) > 0 {
+		from := stats[0].Date
+		u.From = &from
+	}
+	return u
+}
+
+// Coverage devuelve cuánto tiempo hasta now cubren los datos y si cubren la
+// ventana entera. interval es la frecuencia de los checks: el primero puede
+// llegar hasta un intervalo después del inicio de la ventana.
+func (u Uptime) Coverage(now time.Time, interval time.Duration) (time.Duration, bool) {
+	if u.From == nil {
+		return 0, false
+	}
+	return now.Sub(*u.From), !u.From.After(u.Since.Add(interval))
+}
+
+// FormatWindow escribe una duración como ventana: en días si pasa de un
+// día, si no en horas o minutos.
+func FormatWindow(d time.Duration) string {
+	switch {
+	case d > 24*time.Hour:
+		return fmt.Sprintf("%dd", int((d+24*time.Hour-1)/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Round(time.Hour)/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d.Round(time.Minute)/time.Minute))
+	}
+}
+
+// ComputeUptime calcula el uptime a partir del historial de checks.
+func ComputeUptime(service, window string, history []models.CheckResult) Uptime {
+	u := Uptime{Service: service, Window: window, Checks: len(history)}
+	for _, r := range history {
+		switch {
+		case r.Maintenance:
+			u.Maintenance++
+		case r.Status == "UP":
+			u.Up++
+		default:
+			u.Down++
+		}
+	}
+	if counted := u.Up + u.Down; counted > 0 {
+		pct := float64(u.Up) * 100 / float64(counted)
+		u.UptimePercent = &pct
+	}
+	return u
+}
+
+// ParseWindow interpreta duraciones como "24h" o "30d". Además de las
+// unidades de time.ParseDuration acepta días con el sufijo "d".
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, errors.New("ventana inválida: " + s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New("ventana inválida: " + s)
+	}
+	return d, nil
+}
+
+// ComputeDailyUptime calcula el uptime a partir del resumen diario, con el
+// mismo criterio que ComputeUptime.
+func ComputeDailyUptime(service, window string, stats []models.DailyStats) Uptime {
+	u := Uptime{Service: service, Window: window}
+	for _, d := range stats {
+		u.Checks += d.Checks
+		u.Up += d.Up
+		u.Down += d.Down
+		u.Maintenance += d.Maintenance
+	}
+	if counted := u.Up + u.Down; counted > 0 {
+		pct := float64(u.Up) * 100 / float64(counted)
+		u.UptimePercent = &pct
+	}
+	return u
+}
+
+// DayUptime es el uptime de un servicio en un día calendario.
+type DayUptime struct {
+	Date          time.Time `json:"date"` // medianoche UTC del día
+	Checks        int       `json:"checks"`
+	Down          int       `json:"down"`
+	UptimePercent *float64  `json:"uptimePercent"` // nil si no hubo checks computables
+}
+
+// DailyUptime ubica el resumen diario en los últimos days días UTC hasta
+// now, del más antiguo al más reciente. Los días sin resumen quedan sin
+// datos.
+func DailyUptime(stats []models.DailyStats, days int, now time.Time) []DayUptime {
+	first := models.Day(now).AddDate(0, 0, -(days - 1))
+	out := make([]DayUptime, days)
+	for i := range out {
+		out[i].Date = first.AddDate(0, 0, i)
+	}
+	for _, d := range stats {
+		i := int(d.Date.Sub(first) / (24 * time.Hour))
+		if d.Date.Before(first) || i >= days {
+			continue
+		}
+		u := ComputeDailyUptime("", "", []models.DailyStats{d})
+		out[i].Checks = u.Checks
+		out[i].Down = u.Down
+		out[i].UptimePercent = u.UptimePercent
+	}
+	return out
+}
+
+// CoveredDays cuenta los días desde el primero con checks hasta el último
+// de la lista: la ventana que de verdad cubren los datos.
+func CoveredDays(days []DayUptime) int {
+	for i, d := range days {
+		if d.Checks > 0 {
+			return len(days) - i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package statuspage
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+)
+
+// HistoryDays es cuántos días de uptime muestra cada servicio: los que
+// conserva el resumen diario del store.
+const HistoryDays = store.HistoryDays
+
+// DefaultGroupKey es la clave de tag por la que se agrupan los servicios si
+// no se indica otra.
+const DefaultGroupKey = "team"
+
+// ungroupedName es el grupo de los servicios sin el tag de agrupación.
+const ungroupedName = "Otros servicios"
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var page = template.Must(template.New("status.html").Funcs(template.FuncMap{
+	"statusClass": statusClass,
+	"dayClass":    dayClass,
+	"percent":     percent,
+	"days":        days,
+	"dayTitle":    dayTitle,
+	"join":        strings.Join,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// Page es todo lo que muestra la página de estado.
+type Page struct {
+	Generated   time.Time
+	GroupKey    string
+	Platform    report.Platform
+	Groups      []Group
+	Incidents   []models.Incident
+	Maintenance []Maintenance
+}
+
+// Group es un bloque de servicios que comparten el valor del tag de agrupación.
+type Group struct {
+	Name     string
+	Services []Service
+}
+
+// Service es una fila de la página.
+type Service struct {
+	Name      string
+	Status    string
+	LastCheck string
+	Uptime    *float64 // uptime de los días con datos, hasta HistoryDays
+	Covered   int      // días con datos, desde el primer check registrado
+	Days      []report.DayUptime
+}
+
+// Maintenance es una ventana vigente o por venir.
+type Maintenance struct {
+	models.MaintenanceWindow
+	Active bool
+}
+
+// Build arma la página con el estado del store en el instante now.
+func Build(storage store.Storage, groupKey string, now time.Time) Page {
+	if groupKey == "" {
+		groupKey = DefaultGroupKey
+	}
+	services := storage.GetAll()
+	p := Page{
+		Generated: now,
+		GroupKey:  groupKey,
+		Platform:  report.PlatformStatus(services),
+	}
+
+	since := models.Day(now).AddDate(0, 0, -(HistoryDays - 1))
+	groups := map[string]*Group{}
+	for _, s := range services {
+		name, ok := s.TagValue(groupKey)
+		if !ok {
+			name = ungroupedName
+		}
+		g := groups[name]
+		if g == nil {
+			g = &Group{Name: name}
+			groups[name] = g
+		}
+		stats := storage.DailyStats(s.Name, since)
+		days := report.DailyUptime(stats, HistoryDays, now)
+		g.Services = append(g.Services, Service{
+			Name:      s.Name,
+			Status:    s.Status,
+			LastCheck: s.LastCheck,
+			Uptime:    report.ComputeDailyUptime(s.Name, "", stats).UptimePercent,
+			Covered:   report.CoveredDays(days),
+			Days:      days,
+		})
+	}
+	for _, g := range groups {
+		sort.Slice(g.Services, func(i, j int) bool { return g.Services[i].Name < g.Services[j].Name })
+		p.Groups = append(p.Groups, *g)
+	}
+	// Los servicios sin grupo van al final
+	sort.Slice(p.Groups, func(i, j int) bool {
+		if (p.Groups[i].Name == ungroupedName) != (p.Groups[j].Name == ungroupedName) {
+			return p.Groups[j].Name == ungroupedName
+		}
+		return p.Groups[i].Name < p.Groups[j].Name
+	})
+
+	for _, inc := range storage.ListIncidents() {
+		if inc.IsOpen() {
+			p.Incidents = append(p.Incidents, inc)
+		}
+	}
+	for _, w := range storage.ListMaintenance() {
+		if !w.Expired(now) {
+			p.Maintenance = append(p.Maintenance, Maintenance{MaintenanceWindow: w, Active: w.ActiveAt(now)})
+		}
+	}
+	return p
+}
+
+// Render escribe la página en HTML.
+func Render(w io.Writer, p Page) error {
+	return page.Execute(w, p)
+}
+
+func statusClass(status string) string {
+	switch status {
+	case "UP":
+		return "up"
+	case "DOWN":
+		return "down"
+	case report.StatusDegraded:
+		return "degraded"
+	case models.StatusMaintenance:
+		return "maintenance"
+	default:
+		return "unknown"
+	}
+}
+
+func dayClass(d report.DayUptime) string {
+	switch {
+	case d.UptimePercent == nil:
+		return "nodata"
+	case *d.UptimePercent >= 99.9:
+		return "up"
+	case *d.UptimePercent >= 95:
+		return "degraded"
+	default:
+		return "down"
+	}
+}
+
+func percent(p *float64) string {
+	if p == nil {
+		return "sin datos"
+	}
+	return fmt.Sprintf("%.2f%%", *p)
+}
+
+// days escribe una cantidad de días, como "1 día" o "12 días".
+func days(n int) string {
+	if n == 1 {
+		return "1 día"
+	}
+	return fmt.Sprintf("%d días", n)
+}
+
+func dayTitle(d report.DayUptime) string {
+	return d.Date.Format("2006-01-02") + ": " + percent(d.UptimePercent)
+}
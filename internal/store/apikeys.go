@@ -0,0 +1,61 @@
+package store
+
+import (
+	"health-check-app-micro/internal/models"
+	"sort"
+)
+
+// SaveAPIKey adds or replaces an API key.
+func (s *Store) SaveAPIKey(key models.APIKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key.Clone()
+	s.apiKeys[k.ID] = &k
+	_ = s.persistLocked()
+}
+
+// DeleteAPIKey removes an API key, reporting whether it existed.
+func (s *Store) DeleteAPIKey(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.apiKeys[id]; !exists {
+		return false
+	}
+	delete(s.apiKeys, id)
+	_ = s.persistLocked()
+	return true
+}
+
+// ListAPIKeys returns every API key ordered by creation time.
+func (s *Store) ListAPIKeys() []models.APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]models.APIKey, 0, len(s.apiKeys))
+	for _, k := range s.apiKeys {
+		list = append(list, k.Clone())
+	}
+	sortAPIKeys(list)
+	return list
+}
+
+// APIKeyByHash returns the key with the given hash, or nil.
+func (s *Store) APIKeyByHash(hash string) *models.APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if k.Hash == hash {
+			c := k.Clone()
+			return &c
+		}
+	}
+	return nil
+}
+
+func sortAPIKeys(list []models.APIKey) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+}
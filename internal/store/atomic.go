@@ -0,0 +1,96 @@
+package store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic reemplaza path con data sin dejar nunca un archivo a
+// medio escribir: escribe un temporal en el mismo directorio, hace fsync,
+// conserva la versión anterior en path+".bak" y renombra el temporal sobre
+// path. Un crash en cualquier punto deja la versión vieja o la nueva.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op si el rename tuvo éxito
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := rotateBackup(path); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// rotateBackup deja en path+".bak" una copia del contenido actual de path.
+// Se usa un hard link cuando es posible para no copiar el archivo.
+func rotateBackup(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	backup := backupPath(path)
+	_ = os.Remove(backup)
+	if err := os.Link(path, backup); err == nil {
+		return nil
+	}
+	return copyFile(path, backup)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir hace fsync del directorio para que el rename sobreviva a un corte
+// de energía. En sistemas donde no está soportado se ignora.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	defer d.Close()
+	_ = d.Sync()
+	return nil
+}
+
+func backupPath(path string) string {
+	return path + ".bak"
+}
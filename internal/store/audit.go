@@ -0,0 +1,43 @@
+package store
+
+import (
+	"time"
+
+	"health-check-app-micro/internal/models"
+)
+
+// AppendAudit adds an entry to the audit trail.
+func (s *Store) AppendAudit(entry models.AuditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audit = append(s.audit, entry.Clone())
+	s.trimAuditLocked(time.Now())
+	_ = s.persistLocked()
+}
+
+// trimAuditLocked descarta las entradas más antiguas que AuditDays y las que
+// excedan maxAuditEntries. Caller MUST hold s.mu.
+func (s *Store) trimAuditLocked(now time.Time) {
+	cutoff := now.AddDate(0, 0, -AuditDays)
+	drop := 0
+	for drop < len(s.audit) && s.audit[drop].Time.Before(cutoff) {
+		drop++
+	}
+	if over := len(s.audit) - maxAuditEntries; over > drop {
+		drop = over
+	}
+	if drop > 0 {
+		s.audit = append([]models.AuditEntry(nil), s.audit[drop:]...)
+	}
+}
+
+// ListAudit returns the audit trail, most recent first.
+func (s *Store) ListAudit() []models.AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]models.AuditEntry, 0, len(s.audit))
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		list = append(list, s.audit[i].Clone())
+	}
+	return list
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"health-check-app-micro/internal/models"
+	"sort"
+)
+
+// CreateIncident stores a new incident.
+func (s *Store) CreateIncident(incident models.Incident) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := incident.Clone()
+	s.incidents[i.ID] = &i
+	_ = s.persistLocked()
+}
+
+// UpdateIncident applies fn to the stored incident under the store lock and
+// returns a snapshot of the result, or false if it does not exist.
+func (s *Store) UpdateIncident(id string, fn func(*models.Incident)) (*models.Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident, exists := s.incidents[id]
+	if !exists {
+		return nil, false
+	}
+	fn(incident)
+	_ = s.persistLocked()
+	out := incident.Clone()
+	return &out, true
+}
+
+// GetIncident returns a snapshot of the incident, or nil if it does not exist.
+func (s *Store) GetIncident(id string) *models.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident, exists := s.incidents[id]
+	if !exists {
+		return nil
+	}
+	out := incident.Clone()
+	return &out
+}
+
+// ListIncidents returns every incident, most recent first.
+func (s *Store) ListIncidents() []models.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]models.Incident, 0, len(s.incidents))
+	for _, incident := range s.incidents {
+		list = append(list, incident.Clone())
+	}
+	sortIncidents(list)
+	return list
+}
+
+// ActiveIncident returns the open incident of a service, or nil if none.
+func (s *Store) ActiveIncident(service string) *models.Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, incident := range s.incidents {
+		if incident.Service == service && incident.IsOpen() {
+			out := incident.Clone()
+			return &out
+		}
+	}
+	return nil
+}
+
+func sortIncidents(list []models.Incident) {
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.After(list[j].StartedAt) })
+}
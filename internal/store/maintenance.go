@@ -0,0 +1,48 @@
+package store
+
+import (
+	"health-check-app-micro/internal/models"
+	"sort"
+)
+
+// SaveMaintenance adds or replaces a maintenance window.
+func (s *Store) SaveMaintenance(window models.MaintenanceWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := window.Clone()
+	s.maintenance[w.ID] = &w
+	_ = s.persistLocked()
+}
+
+// DeleteMaintenance removes a maintenance window, reporting whether it existed.
+func (s *Store) DeleteMaintenance(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.maintenance[id]; !exists {
+		return false
+	}
+	delete(s.maintenance, id)
+	_ = s.persistLocked()
+	return true
+}
+
+// ListMaintenance returns every maintenance window ordered by start time.
+func (s *Store) ListMaintenance() []models.MaintenanceWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]models.MaintenanceWindow, 0, len(s.maintenance))
+	for _, w := range s.maintenance {
+		list = append(list, w.Clone())
+	}
+	sortMaintenance(list)
+	return list
+}
+
+func sortMaintenance(list []models.MaintenanceWindow) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Start.Equal(list[j].Start) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].Start.Before(list[j].Start)
+	})
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations contiene el esquema del backend SQLite. Cada entrada se aplica
+// una sola vez y en orden; nunca modificar una migración ya publicada, sino
+// agregar una nueva al final.
+//
+// Los servicios, resultados e incidentes se guardan serializados en la
+// columna data; el resto de columnas existen para indexar y filtrar.
+var migrations = []string{
+	// 1: esquema inicial
+	`CREATE TABLE services (
+		name       TEXT PRIMARY KEY,
+		data       TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	CREATE TABLE check_history (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		service    TEXT NOT NULL,
+		checked_at INTEGER NOT NULL,
+		status     TEXT NOT NULL,
+		data       TEXT NOT NULL
+	);
+	CREATE INDEX idx_check_history_service ON check_history(service, checked_at);
+	CREATE TABLE incidents (
+		id          TEXT PRIMARY KEY,
+		service     TEXT NOT NULL,
+		started_at  INTEGER NOT NULL,
+		resolved_at INTEGER,
+		data        TEXT NOT NULL
+	);
+	CREATE INDEX idx_incidents_service ON incidents(service, started_at);`,
+
+	// 2: ventanas de mantenimiento
+	`CREATE TABLE maintenance_windows (
+		id   TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	);`,
+
+	// 3: API keys
+	`CREATE TABLE api_keys (
+		id   TEXT PRIMARY KEY,
+		hash TEXT NOT NULL UNIQUE,
+		data TEXT NOT NULL
+	);`,
+
+	// 4: auditoría de cambios de configuración
+	`CREATE TABLE audit_log (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_id TEXT NOT NULL UNIQUE,
+		at       INTEGER NOT NULL,
+		tenant   TEXT NOT NULL,
+		action   TEXT NOT NULL,
+		data     TEXT NOT NULL
+	);
+	CREATE INDEX idx_audit_log_tenant ON audit_log(tenant, at);`,
+}
+
+// migrate aplica las migraciones pendientes dentro de una transacción cada una.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migración %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migración %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migración %d: %w", version, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // driver SQLite en Go puro, sin cgo
+)
+
+const defaultSQLiteFile = "health-check.db"
+
+// historyRetention es cuánto historial de checks conserva el backend SQLite.
+const historyRetention = HistoryDays * 24 * time.Hour
+
+// SQLiteStore es el backend de Storage sobre una base SQLite embebida.
+// A diferencia del backend JSON, cada actualización toca solo la fila del
+// servicio afectado y el historial de checks se conserva entre reinicios.
+type SQLiteStore struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// OpenSQLite abre (o crea) la base en path y aplica las migraciones pendientes.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite admite un único escritor; una sola conexión evita SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) RegisterService(service models.Microservice) {
+	data, err := json.Marshal(service)
+	if err != nil {
+		utils.LogError("❌ Error serializando servicio " + service.Name + ": " + err.Error())
+		return
+	}
+	_, err = s.db.Exec(`INSERT INTO services (name, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		service.Name, string(data), time.Now().UnixMilli())
+	if err != nil {
+		utils.LogError("❌ Error guardando servicio " + service.Name + ": " + err.Error())
+	}
+}
+
+// RegisterServiceLimited implementa Storage con una sola sentencia, para que
+// el conteo y el alta sean atómicos.
+func (s *SQLiteStore) RegisterServiceLimited(service models.Microservice, max int) (bool, error) {
+	data, err := json.Marshal(service)
+	if err != nil {
+		return false, fmt.Errorf("serializando servicio %s: %w", service.Name, err)
+	}
+	// Los servicios del tenant por defecto no llevan prefijo
+	filter, filterArgs := `instr(name, '/') = 0`, []any{}
+	if tenant, _ := models.SplitName(service.Name); tenant != "" {
+		prefix := tenant + "/"
+		filter, filterArgs = `substr(name, 1, ?) = ?`, []any{len(prefix), prefix}
+	}
+	args := []any{service.Name, string(data), time.Now().UnixMilli(), max, service.Name}
+	args = append(args, filterArgs...)
+	args = append(args, max)
+	res, err := s.db.Exec(`INSERT INTO services (name, data, updated_at)
+		SELECT ?, ?, ? WHERE ? <= 0
+			OR EXISTS (SELECT 1 FROM services WHERE name = ?)
+			OR (SELECT COUNT(*) FROM services WHERE `+filter+`) < ?
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`, args...)
+	if err != nil {
+		return false, fmt.Errorf("guardando servicio %s: %w", service.Name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("guardando servicio %s: %w", service.Name, err)
+	}
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) GetAll() map[string]*models.Microservice {
+	all := make(map[string]*models.Microservice)
+	rows, err := s.db.Query(`SELECT data FROM services`)
+	if err != nil {
+		utils.LogError("❌ Error leyendo servicios: " + err.Error())
+		return all
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			utils.LogError("❌ Error leyendo servicio: " + err.Error())
+			continue
+		}
+		var m models.Microservice
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			utils.LogError("❌ Error decodificando servicio: " + err.Error())
+			continue
+		}
+		all[m.Name] = &m
+	}
+	return all
+}
+
+func (s *SQLiteStore) Get(name string) *models.Microservice {
+	m, err := getService(s.db, name)
+	if err != nil {
+		utils.LogError("❌ Error leyendo servicio " + name + ": " + err.Error())
+		return nil
+	}
+	return m
+}
+
+func (s *SQLiteStore) DeleteService(name string) bool {
+	deleted := false
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec(`DELETE FROM services WHERE name = ?`, name)
+		if err != nil {
+			return err
+		}
+		n, _ := res.RowsAffected()
+		deleted = n > 0
+		_, err = tx.Exec(`DELETE FROM check_history WHERE service = ?`, name)
+		return err
+	})
+	if err != nil {
+		utils.LogError("❌ Error eliminando servicio " + name + ": " + err.Error())
+		return false
+	}
+	return deleted
+}
+
+func (s *SQLiteStore) SetPaused(name string, paused bool) bool {
+	return s.updateService(name, func(m *models.Microservice, tx *sql.Tx) error {
+		m.Paused = paused
+		return nil
+	})
+}
+
+func (s *SQLiteStore) UpdateService(name string, status string, lastCheck string) {
+	s.updateService(name, func(m *models.Microservice, tx *sql.Tx) error {
+		m.Status = status
+		m.LastCheck = lastCheck
+		return nil
+	})
+}
+
+func (s *SQLiteStore) RecordResult(name string, result models.CheckResult) (string, bool) {
+	var previous string
+	found := s.updateService(name, func(m *models.Microservice, tx *sql.Tx) error {
+		previous = m.Status
+		m.Status = result.Status
+		m.LastCheck = result.Timestamp.Format(time.RFC3339)
+		m.LastResult = &result
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO check_history (service, checked_at, status, data) VALUES (?, ?, ?, ?)`,
+			name, result.Timestamp.UnixMilli(), result.Status, string(data))
+		return err
+	})
+	s.pruneHistory()
+	return previous, found
+}
+
+func (s *SQLiteStore) History(name string, since time.Time) []models.CheckResult {
+	rows, err := s.db.Query(`SELECT data FROM check_history
+		WHERE service = ? AND checked_at >= ? ORDER BY checked_at, id`, name, since.UnixMilli())
+	if err != nil {
+		utils.LogError("❌ Error leyendo historial de " + name + ": " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	var out []models.CheckResult
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			continue
+		}
+		var r models.CheckResult
+		if json.Unmarshal([]byte(data), &r) == nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// DailyStats agrupa el historial por día UTC; el historial detallado se
+// conserva los mismos HistoryDays días que el resumen.
+func (s *SQLiteStore) DailyStats(name string, since time.Time) []models.DailyStats {
+	rows, err := s.db.Query(`SELECT checked_at / 86400000 AS day, COUNT(*),
+			SUM(CASE WHEN json_extract(data, '$.maintenance') THEN 0 WHEN status = 'UP' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN json_extract(data, '$.maintenance') THEN 0 WHEN status = 'UP' THEN 0 ELSE 1 END),
+			SUM(CASE WHEN json_extract(data, '$.maintenance') THEN 1 ELSE 0 END)
+		FROM check_history WHERE service = ? AND checked_at >= ?
+		GROUP BY day ORDER BY day`, name, models.Day(since).UnixMilli())
+	if err != nil {
+		utils.LogError("❌ Error leyendo resumen diario de " + name + ": " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	var out []models.DailyStats
+	for rows.Next() {
+		var day int64
+		var d models.DailyStats
+		if err := rows.Scan(&day, &d.Checks, &d.Up, &d.Down, &d.Maintenance); err != nil {
+			continue
+		}
+		d.Date = time.UnixMilli(day * 86400000).UTC()
+		out = append(out, d)
+	}
+	return out
+}
+
+// Ping informa si la base acepta escrituras, tomando y soltando el lock de
+// escritura sin modificar nada.
+func (s *SQLiteStore) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `ROLLBACK`)
+	return err
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// updateService lee el servicio, aplica fn y lo guarda, todo en una transacción.
+// Si el servicio no existe no hace nada, igual que el backend JSON, y devuelve false.
+func (s *SQLiteStore) updateService(name string, fn func(*models.Microservice, *sql.Tx) error) bool {
+	found := false
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		m, err := getService(tx, name)
+		if err != nil || m == nil {
+			return err
+		}
+		found = true
+		if err := fn(m, tx); err != nil {
+			return err
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE services SET data = ?, updated_at = ? WHERE name = ?`,
+			string(data), time.Now().UnixMilli(), name)
+		return err
+	})
+	if err != nil {
+		utils.LogError("❌ Error actualizando servicio " + name + ": " + err.Error())
+		return false
+	}
+	return found
+}
+
+// pruneHistory borra, como mucho una vez por hora, el historial más antiguo
+// que historyRetention y la auditoría más antigua que AuditDays.
+func (s *SQLiteStore) pruneHistory() {
+	s.mu.Lock()
+	if time.Since(s.lastPrune) < time.Hour {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.mu.Unlock()
+
+	cutoff := time.Now().Add(-historyRetention).UnixMilli()
+	if _, err := s.db.Exec(`DELETE FROM check_history WHERE checked_at < ?`, cutoff); err != nil {
+		utils.LogError("❌ Error depurando historial: " + err.Error())
+	}
+	auditCutoff := time.Now().AddDate(0, 0, -AuditDays).UnixMilli()
+	if _, err := s.db.Exec(`DELETE FROM audit_log WHERE at < ?`, auditCutoff); err != nil {
+		utils.LogError("❌ Error depurando auditoría: " + err.Error())
+	}
+}
+
+// queryer es lo común entre *sql.DB y *sql.Tx que usan las lecturas.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getService(q queryer, name string) (*models.Microservice, error) {
+	var data string
+	err := q.QueryRow(`SELECT data FROM services WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m models.Microservice
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func withTx(db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
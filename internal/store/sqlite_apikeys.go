@@ -0,0 +1,71 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+)
+
+func (s *SQLiteStore) SaveAPIKey(key models.APIKey) {
+	data, err := json.Marshal(key)
+	if err == nil {
+		_, err = s.db.Exec(`INSERT INTO api_keys (id, hash, data) VALUES (?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET hash = excluded.hash, data = excluded.data`, key.ID, key.Hash, string(data))
+	}
+	if err != nil {
+		utils.LogError("❌ Error guardando API key " + key.ID + ": " + err.Error())
+	}
+}
+
+func (s *SQLiteStore) DeleteAPIKey(id string) bool {
+	res, err := s.db.Exec(`DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		utils.LogError("❌ Error eliminando API key " + id + ": " + err.Error())
+		return false
+	}
+	n, _ := res.RowsAffected()
+	return n > 0
+}
+
+func (s *SQLiteStore) ListAPIKeys() []models.APIKey {
+	rows, err := s.db.Query(`SELECT data FROM api_keys`)
+	if err != nil {
+		utils.LogError("❌ Error leyendo API keys: " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	list := []models.APIKey{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			continue
+		}
+		var k models.APIKey
+		if json.Unmarshal([]byte(data), &k) == nil {
+			list = append(list, k)
+		}
+	}
+	sortAPIKeys(list)
+	return list
+}
+
+func (s *SQLiteStore) APIKeyByHash(hash string) *models.APIKey {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM api_keys WHERE hash = ?`, hash).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		utils.LogError("❌ Error leyendo API key: " + err.Error())
+		return nil
+	}
+	var k models.APIKey
+	if err := json.Unmarshal([]byte(data), &k); err != nil {
+		utils.LogError("❌ Error decodificando API key: " + err.Error())
+		return nil
+	}
+	return &k
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"encoding/json"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+)
+
+func (s *SQLiteStore) AppendAudit(entry models.AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = s.db.Exec(`INSERT INTO audit_log (entry_id, at, tenant, action, data) VALUES (?, ?, ?, ?, ?)`,
+			entry.ID, entry.Time.UnixMilli(), entry.Tenant, entry.Action, string(data))
+	}
+	if err != nil {
+		utils.LogError("❌ Error guardando auditoría " + entry.Action + ": " + err.Error())
+	}
+}
+
+func (s *SQLiteStore) ListAudit() []models.AuditEntry {
+	rows, err := s.db.Query(`SELECT data FROM audit_log ORDER BY id DESC`)
+	if err != nil {
+		utils.LogError("❌ Error leyendo auditoría: " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	list := []models.AuditEntry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			continue
+		}
+		var e models.AuditEntry
+		if json.Unmarshal([]byte(data), &e) == nil {
+			list = append(list, e)
+		}
+	}
+	return list
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+)
+
+func (s *SQLiteStore) CreateIncident(incident models.Incident) {
+	if err := saveIncident(s.db, &incident); err != nil {
+		utils.LogError("❌ Error guardando incidente " + incident.ID + ": " + err.Error())
+	}
+}
+
+func (s *SQLiteStore) UpdateIncident(id string, fn func(*models.Incident)) (*models.Incident, bool) {
+	var updated *models.Incident
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		incident, err := scanIncident(tx.QueryRow(`SELECT data FROM incidents WHERE id = ?`, id))
+		if err != nil || incident == nil {
+			return err
+		}
+		fn(incident)
+		updated = incident
+		return saveIncident(tx, incident)
+	})
+	if err != nil {
+		utils.LogError("❌ Error actualizando incidente " + id + ": " + err.Error())
+		return nil, false
+	}
+	return updated, updated != nil
+}
+
+func (s *SQLiteStore) GetIncident(id string) *models.Incident {
+	incident, err := scanIncident(s.db.QueryRow(`SELECT data FROM incidents WHERE id = ?`, id))
+	if err != nil {
+		utils.LogError("❌ Error leyendo incidente " + id + ": " + err.Error())
+		return nil
+	}
+	return incident
+}
+
+func (s *SQLiteStore) ListIncidents() []models.Incident {
+	rows, err := s.db.Query(`SELECT data FROM incidents ORDER BY started_at DESC`)
+	if err != nil {
+		utils.LogError("❌ Error leyendo incidentes: " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	list := []models.Incident{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			continue
+		}
+		var incident models.Incident
+		if json.Unmarshal([]byte(data), &incident) == nil {
+			list = append(list, incident)
+		}
+	}
+	return list
+}
+
+func (s *SQLiteStore) ActiveIncident(service string) *models.Incident {
+	incident, err := scanIncident(s.db.QueryRow(`SELECT data FROM incidents
+		WHERE service = ? AND resolved_at IS NULL ORDER BY started_at DESC LIMIT 1`, service))
+	if err != nil {
+		utils.LogError("❌ Error leyendo incidente activo de " + service + ": " + err.Error())
+		return nil
+	}
+	return incident
+}
+
+// execer es lo común entre *sql.DB y *sql.Tx que usan las escrituras.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func saveIncident(e execer, incident *models.Incident) error {
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return err
+	}
+	var resolvedAt any
+	if incident.ResolvedAt != nil {
+		resolvedAt = incident.ResolvedAt.UnixMilli()
+	}
+	_, err = e.Exec(`INSERT INTO incidents (id, service, started_at, resolved_at, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET resolved_at = excluded.resolved_at, data = excluded.data`,
+		incident.ID, incident.Service, incident.StartedAt.UnixMilli(), resolvedAt, string(data))
+	return err
+}
+
+func scanIncident(row *sql.Row) (*models.Incident, error) {
+	var data string
+	err := row.Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var incident models.Incident
+	if err := json.Unmarshal([]byte(data), &incident); err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
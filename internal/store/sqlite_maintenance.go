@@ -0,0 +1,51 @@
+package store
+
+import (
+	"encoding/json"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
+)
+
+func (s *SQLiteStore) SaveMaintenance(window models.MaintenanceWindow) {
+	data, err := json.Marshal(window)
+	if err == nil {
+		_, err = s.db.Exec(`INSERT INTO maintenance_windows (id, data) VALUES (?, ?)
+			ON CONFLICT(id) DO UPDATE SET data = excluded.data`, window.ID, string(data))
+	}
+	if err != nil {
+		utils.LogError("❌ Error guardando ventana de mantenimiento " + window.ID + ": " + err.Error())
+	}
+}
+
+func (s *SQLiteStore) DeleteMaintenance(id string) bool {
+	res, err := s.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		utils.LogError("❌ Error eliminando ventana de mantenimiento " + id + ": " + err.Error())
+		return false
+	}
+	n, _ := res.RowsAffected()
+	return n > 0
+}
+
+func (s *SQLiteStore) ListMaintenance() []models.MaintenanceWindow {
+	rows, err := s.db.Query(`SELECT data FROM maintenance_windows`)
+	if err != nil {
+		utils.LogError("❌ Error leyendo ventanas de mantenimiento: " + err.Error())
+		return nil
+	}
+	defer rows.Close()
+
+	list := []models.MaintenanceWindow{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			continue
+		}
+		var w models.MaintenanceWindow
+		if json.Unmarshal([]byte(data), &w) == nil {
+			list = append(list, w)
+		}
+	}
+	sortMaintenance(list)
+	return list
+}
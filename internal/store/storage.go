@@ -0,0 +1,100 @@
+package store
+
+import (
+	"fmt"
+	"health-check-app-micro/internal/models"
+	"strings"
+	"time"
+)
+
+// HistoryDays es cuántos días de resumen diario conservan los backends.
+const HistoryDays = 90
+
+// AuditDays es cuántos días de auditoría conservan los backends.
+const AuditDays = 365
+
+// Backends de almacenamiento soportados por Open.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Storage es la interfaz común de los backends de persistencia. Los métodos
+// de escritura son best-effort: los errores se registran en el log para no
+// bloquear el monitoreo.
+//
+// Las lecturas devuelven copias: modificarlas no afecta al store, y los
+// cambios solo se aplican a través de los métodos de escritura.
+type Storage interface {
+	RegisterService(service models.Microservice)
+	// RegisterServiceLimited registra el servicio si ya existe o si su
+	// tenant tiene menos de max servicios, comprobándolo de forma atómica
+	// con el alta. Con max 0 no hay límite. Devuelve false si se alcanzó
+	// el máximo, y un error si no se pudo guardar.
+	RegisterServiceLimited(service models.Microservice, max int) (bool, error)
+	GetAll() map[string]*models.Microservice
+	Get(name string) *models.Microservice
+	UpdateService(name string, status string, lastCheck string)
+	// DeleteService elimina el servicio y su historial; sus incidentes se
+	// conservan. Devuelve false si no existía.
+	DeleteService(name string) bool
+	// SetPaused pausa o reanuda los checks programados del servicio.
+	SetPaused(name string, paused bool) bool
+
+	// RecordResult guarda el resultado como último check del servicio y lo
+	// agrega al historial. Devuelve el estado previo del servicio, leído de
+	// forma atómica con la actualización, y false si el servicio no existe.
+	RecordResult(name string, result models.CheckResult) (string, bool)
+
+	// History devuelve los resultados de un servicio desde since, del más
+	// antiguo al más reciente.
+	History(name string, since time.Time) []models.CheckResult
+	// DailyStats devuelve el resumen por día UTC de los checks del servicio
+	// desde el día de since, del más antiguo al más reciente. Se conserva
+	// HistoryDays días aunque el historial detallado sea más corto.
+	DailyStats(name string, since time.Time) []models.DailyStats
+
+	// Incidentes. UpdateIncident aplica fn de forma atómica sobre el
+	// incidente guardado y devuelve una copia del resultado.
+	CreateIncident(incident models.Incident)
+	UpdateIncident(id string, fn func(*models.Incident)) (*models.Incident, bool)
+	GetIncident(id string) *models.Incident
+	ListIncidents() []models.Incident // más recientes primero
+	ActiveIncident(service string) *models.Incident
+
+	// Ventanas de mantenimiento.
+	SaveMaintenance(window models.MaintenanceWindow)
+	DeleteMaintenance(id string) bool
+	ListMaintenance() []models.MaintenanceWindow // ordenadas por inicio
+
+	// API keys. Se buscan por el hash de la key presentada.
+	SaveAPIKey(key models.APIKey)
+	DeleteAPIKey(id string) bool
+	ListAPIKeys() []models.APIKey // ordenadas por creación
+	APIKeyByHash(hash string) *models.APIKey
+
+	// Auditoría de cambios de configuración, solo de agregado. Las entradas
+	// más antiguas que AuditDays se descartan.
+	AppendAudit(entry models.AuditEntry)
+	ListAudit() []models.AuditEntry // más recientes primero
+
+	// Ping devuelve un error si el backend no puede persistir cambios.
+	Ping() error
+	Close() error
+}
+
+// Open crea el backend indicado. Con backend vacío se usa el archivo JSON.
+// Si path está vacío se usa el archivo por defecto en el directorio de trabajo.
+func Open(backend, path string) (Storage, error) {
+	switch strings.ToLower(backend) {
+	case "", BackendJSON:
+		return OpenStore(path)
+	case BackendSQLite:
+		if path == "" {
+			path = defaultSQLiteFile
+		}
+		return OpenSQLite(path)
+	default:
+		return nil, fmt.Errorf("backend de almacenamiento desconocido: %q", backend)
+	}
+}
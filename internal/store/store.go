@@ -1,65 +1,205 @@
 package store
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"health-check-app-micro/internal/models"
+	"health-check-app-micro/pkg/utils"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 )
 
 const defaultStoreFile = "services.json"
 
+// persistDelay agrupa las escrituras: todas las actualizaciones que llegan
+// dentro de esta ventana se guardan en una sola escritura del archivo.
+const persistDelay = 500 * time.Millisecond
+
+// detailedHistory es cuánto historial detallado guarda en memoria el backend
+// JSON por servicio: las ventanas de hasta un día de report.WindowUptime se
+// calculan con él, así que cubre un día más un margen. El historial
+// detallado no se persiste en el archivo, solo su resumen diario; para
+// conservarlo entre reinicios usar el backend SQLite.
+const detailedHistory = 25 * time.Hour
+
+// historyCap es la cantidad de checks que entran en detailedHistory a la
+// frecuencia del servicio, con un margen para los checks manuales.
+func historyCap(frequency int) int {
+	if frequency < 10 {
+		frequency = 10
+	}
+	n := int(detailedHistory/time.Second) / frequency
+	return n + n/10 + 100
+}
+
+// maxAuditEntries limita la auditoría del backend JSON, que se reescribe
+// entera en el archivo con cada cambio: además de AuditDays se conservan
+// solo las últimas entradas.
+const maxAuditEntries = 5000
+
+// Store es el backend de Storage basado en un archivo JSON.
 type Store struct {
-	mu            sync.Mutex
-	Microservices map[string]*models.Microservice
-	filePath      string
+	mu          sync.Mutex
+	services    map[string]*models.Microservice
+	filePath    string
+	history     map[string][]models.CheckResult
+	daily       map[string][]models.DailyStats // resumen de los últimos HistoryDays días
+	incidents   map[string]*models.Incident
+	maintenance map[string]*models.MaintenanceWindow
+	apiKeys     map[string]*models.APIKey
+	audit       []models.AuditEntry // en orden de registro
+
+	flushTimer *time.Timer // escritura pendiente, nil si no hay cambios sin guardar
+	flushErr   error       // error de la última escritura, nil si fue exitosa
 }
 
 // NewStore creates a new store and attempts to load persisted services from disk.
 func NewStore() *Store {
-	wd, _ := os.Getwd()
-	path := filepath.Join(wd, defaultStoreFile)
-	return NewStoreWithPath(path)
+	return NewStoreWithPath(defaultStorePath())
 }
 
 // NewStoreWithPath crea un Store que persiste en la ruta indicada.
 // Esto es útil para tests que quieran aislar el almacenamiento en un
 // archivo temporal y evitar escribir en el directorio de trabajo del repo.
+// Los errores de carga solo se registran en el log; para tratarlos usar OpenStore.
 func NewStoreWithPath(path string) *Store {
-	s := &Store{
-		Microservices: make(map[string]*models.Microservice),
-		filePath:      path,
+	s, err := OpenStore(path)
+	if err != nil {
+		utils.LogError("❌ Error cargando " + path + ": " + err.Error())
 	}
-	// load existing services if file exists
-	s.loadFromFile()
 	return s
 }
 
+// OpenStore crea un Store que persiste en path y carga su contenido. Si el
+// archivo está corrupto se intenta recuperar la copia de respaldo; si
+// tampoco es válida se devuelve el error junto con un store vacío.
+func OpenStore(path string) (*Store, error) {
+	if path == "" {
+		path = defaultStorePath()
+	}
+	s := &Store{
+		services:    make(map[string]*models.Microservice),
+		filePath:    path,
+		history:     make(map[string][]models.CheckResult),
+		daily:       make(map[string][]models.DailyStats),
+		incidents:   make(map[string]*models.Incident),
+		maintenance: make(map[string]*models.MaintenanceWindow),
+		apiKeys:     make(map[string]*models.APIKey),
+	}
+
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return s, err
+		}
+	}
+
+	err := s.loadFromFile(path)
+	if err == nil {
+		return s, nil
+	}
+	if _, statErr := os.Stat(backupPath(path)); statErr == nil && s.loadFromFile(backupPath(path)) == nil {
+		utils.LogWarn(fmt.Sprintf("⚠️ %s inválido (%v), se recuperó la copia de respaldo", path, err))
+		return s, nil
+	}
+	return s, fmt.Errorf("cargando %s: %w", path, err)
+}
+
+func defaultStorePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, defaultStoreFile)
+}
+
+// RegisterService adds or replaces a service. The store keeps its own copy.
 func (s *Store) RegisterService(service models.Microservice) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Microservices[service.Name] = &service
+	m := service.Clone()
+	s.services[service.Name] = &m
 	// persist current state (best-effort)
 	_ = s.persistLocked()
 }
 
+// RegisterServiceLimited implementa Storage.
+func (s *Store) RegisterServiceLimited(service models.Microservice, max int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.services[service.Name]; !exists && max > 0 {
+		tenant, _ := models.SplitName(service.Name)
+		count := 0
+		for name := range s.services {
+			if t, _ := models.SplitName(name); t == tenant {
+				count++
+			}
+		}
+		if count >= max {
+			return false, nil
+		}
+	}
+	m := service.Clone()
+	s.services[service.Name] = &m
+	return true, s.persistLocked()
+}
+
+// GetAll returns a snapshot of every service. The returned values are copies
+// and may be used freely without holding any lock.
 func (s *Store) GetAll() map[string]*models.Microservice {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.Microservices
+	all := make(map[string]*models.Microservice, len(s.services))
+	for name, service := range s.services {
+		m := service.Clone()
+		all[name] = &m
+	}
+	return all
 }
 
+// Get returns a snapshot of the named service, or nil if it does not exist.
 func (s *Store) Get(name string) *models.Microservice {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.Microservices[name]
+	service, exists := s.services[name]
+	if !exists {
+		return nil
+	}
+	m := service.Clone()
+	return &m
+}
+
+// DeleteService removes a service and its in-memory history.
+func (s *Store) DeleteService(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.services[name]; !exists {
+		return false
+	}
+	delete(s.services, name)
+	delete(s.history, name)
+	delete(s.daily, name)
+	_ = s.persistLocked()
+	return true
+}
+
+// SetPaused pauses or resumes the scheduled checks of a service.
+func (s *Store) SetPaused(name string, paused bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	service, exists := s.services[name]
+	if !exists {
+		return false
+	}
+	service.Paused = paused
+	_ = s.persistLocked()
+	return true
 }
 
 func (s *Store) UpdateService(name string, status string, lastCheck string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if service, exists := s.Microservices[name]; exists {
+	if service, exists := s.services[name]; exists {
 		service.Status = status
 		service.LastCheck = lastCheck
 		// persist change
@@ -67,52 +207,234 @@ func (s *Store) UpdateService(name string, status string, lastCheck string) {
 	}
 }
 
-// persistLocked writes the current services to the configured file.
+// RecordResult stores the outcome of a check as the service's last result,
+// updating Status and LastCheck accordingly. It returns the status the
+// service had before the update, read under the same lock, so concurrent
+// checks of one service observe each transition exactly once.
+func (s *Store) RecordResult(name string, result models.CheckResult) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	service, exists := s.services[name]
+	if !exists {
+		return "", false
+	}
+	previous := service.Status
+	service.Status = result.Status
+	service.LastCheck = result.Timestamp.Format(time.RFC3339)
+	service.LastResult = result.Clone()
+	_ = s.persistLocked()
+
+	h := append(s.history[name], result)
+	if max := historyCap(service.Frequency); len(h) > max {
+		h = h[len(h)-max:]
+	}
+	s.history[name] = h
+	s.addDailyLocked(name, result)
+	return previous, true
+}
+
+// addDailyLocked suma el resultado al resumen de su día y descarta los días
+// anteriores a HistoryDays. Caller MUST hold s.mu.
+func (s *Store) addDailyLocked(name string, result models.CheckResult) {
+	days := s.daily[name]
+	day := models.Day(result.Timestamp)
+	i := len(days)
+	for i > 0 && days[i-1].Date.After(day) {
+		i--
+	}
+	if i == 0 || !days[i-1].Date.Equal(day) {
+		days = append(days, models.DailyStats{})
+		copy(days[i+1:], days[i:])
+		days[i] = models.DailyStats{Date: day}
+		i++
+	}
+	days[i-1].Add(result)
+
+	cutoff := models.Day(time.Now()).AddDate(0, 0, -HistoryDays)
+	for len(days) > 0 && days[0].Date.Before(cutoff) {
+		days = days[1:]
+	}
+	s.daily[name] = days
+}
+
+// DailyStats devuelve el resumen diario del servicio desde el día de since.
+func (s *Store) DailyStats(name string, since time.Time) []models.DailyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from := models.Day(since)
+	var out []models.DailyStats
+	for _, d := range s.daily[name] {
+		if !d.Date.Before(from) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// History returns the in-memory check history of a service since the given time.
+func (s *Store) History(name string, since time.Time) []models.CheckResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.CheckResult
+	for _, r := range s.history[name] {
+		if !r.Timestamp.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Close writes any pending change. The JSON store holds no other resources.
+func (s *Store) Close() error {
+	return s.Flush()
+}
+
+// Flush writes any pending change to disk immediately.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.flushTimer == nil {
+		return nil
+	}
+	return s.flushLocked()
+}
+
+// persistLocked schedules a write of the current services. Writes are
+// debounced by persistDelay so a burst of updates produces a single write.
 // Caller MUST hold s.mu.
 func (s *Store) persistLocked() error {
-	list := make([]models.Microservice, 0, len(s.Microservices))
-	for _, v := range s.Microservices {
-		// copy value to avoid pointing to loop var
-		m := *v
-		list = append(list, m)
+	if s.flushTimer != nil {
+		return nil
 	}
+	s.flushTimer = time.AfterFunc(persistDelay, func() {
+		if err := s.Flush(); err != nil {
+			utils.LogError("❌ Error guardando " + s.filePath + ": " + err.Error())
+		}
+	})
+	return nil
+}
 
-	data, err := json.MarshalIndent(list, "", "  ")
+// fileDocument is the on-disk layout of the JSON store. Older versions of
+// the file held only the list of services; loadFromFile still accepts them.
+type fileDocument struct {
+	Version     int                        `json:"version"`
+	Services    []models.Microservice      `json:"services"`
+	Incidents   []models.Incident          `json:"incidents"`
+	Maintenance []models.MaintenanceWindow `json:"maintenance"`
+	APIKeys     []models.APIKey            `json:"apiKeys,omitempty"`
+	Audit       []models.AuditEntry        `json:"audit,omitempty"`
+	// Daily es el resumen diario del historial de cada servicio
+	Daily map[string][]models.DailyStats `json:"daily,omitempty"`
+}
+
+const fileVersion = 2
+
+// flushLocked atomically writes the current state to the configured file.
+// Caller MUST hold s.mu.
+func (s *Store) flushLocked() error {
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+
+	doc := fileDocument{
+		Version:     fileVersion,
+		Services:    make([]models.Microservice, 0, len(s.services)),
+		Incidents:   make([]models.Incident, 0, len(s.incidents)),
+		Maintenance: make([]models.MaintenanceWindow, 0, len(s.maintenance)),
+	}
+	for _, v := range s.apiKeys {
+		doc.APIKeys = append(doc.APIKeys, *v)
+	}
+	sortAPIKeys(doc.APIKeys)
+	doc.Audit = s.audit
+	doc.Daily = s.daily
+	for _, v := range s.services {
+		doc.Services = append(doc.Services, *v)
+	}
+	for _, v := range s.incidents {
+		doc.Incidents = append(doc.Incidents, *v)
+	}
+	for _, v := range s.maintenance {
+		doc.Maintenance = append(doc.Maintenance, *v)
+	}
+	sortMaintenance(doc.Maintenance)
+	sort.Slice(doc.Services, func(i, j int) bool { return doc.Services[i].Name < doc.Services[j].Name })
+	sort.Slice(doc.Incidents, func(i, j int) bool { return doc.Incidents[i].StartedAt.Before(doc.Incidents[j].StartedAt) })
+
+	data, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	// ensure directory exists
-	dir := filepath.Dir(s.filePath)
-	if dir != "" && dir != "." {
-		_ = os.MkdirAll(dir, 0755)
+	s.flushErr = writeFileAtomic(s.filePath, data, 0644)
+	return s.flushErr
+}
+
+// Ping informa si el store puede persistir: falla si la última escritura
+// falló o si no se puede crear un archivo en el directorio de datos.
+func (s *Store) Ping() error {
+	s.mu.Lock()
+	err := s.flushErr
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("última escritura fallida: %w", err)
 	}
 
-	return os.WriteFile(s.filePath, data, 0644)
+	f, err := os.CreateTemp(filepath.Dir(s.filePath), ".ping-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
 }
 
-// loadFromFile loads persisted services into the store. It acquires the lock
-// while populating the internal map.
-func (s *Store) loadFromFile() error {
-	if _, err := os.Stat(s.filePath); os.IsNotExist(err) {
+// loadFromFile loads persisted services from path into the store. A missing
+// file is not an error. It acquires the lock while populating the internal map.
+func (s *Store) loadFromFile(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil
 	}
 
-	data, err := os.ReadFile(s.filePath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
-	var list []models.Microservice
-	if err := json.Unmarshal(data, &list); err != nil {
+	var doc fileDocument
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		// formato anterior: solo la lista de servicios
+		err = json.Unmarshal(data, &doc.Services)
+	} else {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
 		return err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for _, ms := range list {
+	for _, ms := range doc.Services {
 		m := ms
-		s.Microservices[m.Name] = &m
+		s.services[m.Name] = &m
+	}
+	for _, inc := range doc.Incidents {
+		i := inc
+		s.incidents[i.ID] = &i
+	}
+	for _, mw := range doc.Maintenance {
+		w := mw
+		s.maintenance[w.ID] = &w
+	}
+	for _, ak := range doc.APIKeys {
+		k := ak
+		s.apiKeys[k.ID] = &k
+	}
+	s.audit = doc.Audit
+	s.trimAuditLocked(time.Now())
+	for name, days := range doc.Daily {
+		s.daily[name] = days
 	}
 	return nil
 }
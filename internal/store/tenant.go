@@ -0,0 +1,350 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"health-check-app-micro/internal/models"
+)
+
+// tenantView es la vista de un tenant sobre el store compartido. Los
+// servicios se guardan con la clave "tenant/nombre" (solo el nombre en el
+// tenant por defecto); la vista agrega el prefijo al escribir y lo quita al
+// leer, y solo expone los servicios, incidentes, ventanas y keys de su
+// tenant. Así los nombres son únicos por tenant y el resto del código
+// trabaja con nombres cortos sin conocer los tenants.
+//
+// Es un valor comparable: dos vistas del mismo tenant sobre el mismo store
+// son iguales, lo que permite usarlas como clave (por ejemplo en el scheduler).
+type tenantView struct {
+	root   Storage
+	tenant string
+}
+
+// ForTenant devuelve la vista del tenant indicado sobre storage. El tenant
+// vacío o "default" es el tenant por defecto.
+func ForTenant(storage Storage, tenant string) Storage {
+	if v, ok := storage.(tenantView); ok {
+		storage = v.root
+	}
+	return tenantView{root: storage, tenant: models.NormalizeTenant(tenant)}
+}
+
+// Tenants devuelve los tenants que tienen servicios en storage, ordenados,
+// empezando por el tenant por defecto (vacío). Sobre una vista devuelve
+// solo su tenant.
+func Tenants(storage Storage) []string {
+	if v, ok := storage.(tenantView); ok {
+		return []string{v.tenant}
+	}
+	seen := map[string]bool{"": true}
+	for name := range storage.GetAll() {
+		tenant, _ := models.SplitName(name)
+		seen[tenant] = true
+	}
+	out := make([]string, 0, len(seen))
+	for t := range seen {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (v tenantView) qualify(name string) string {
+	return models.QualifiedName(v.tenant, name)
+}
+
+func (v tenantView) qualifyAll(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = v.qualify(n)
+	}
+	return out
+}
+
+// owns indica si la clave global pertenece al tenant de la vista.
+func (v tenantView) owns(qualified string) bool {
+	tenant, _ := models.SplitName(qualified)
+	return tenant == v.tenant
+}
+
+func (v tenantView) local(qualified string) string {
+	_, name := models.SplitName(qualified)
+	return name
+}
+
+func (v tenantView) localAll(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = v.local(n)
+	}
+	return out
+}
+
+func (v tenantView) qualifyResult(r models.CheckResult) models.CheckResult {
+	r.Name = v.qualify(r.Name)
+	r.ImpactedBy = v.qualifyAll(r.ImpactedBy)
+	return r
+}
+
+func (v tenantView) localResult(r models.CheckResult) models.CheckResult {
+	r.Name = v.local(r.Name)
+	r.ImpactedBy = v.localAll(r.ImpactedBy)
+	return r
+}
+
+func (v tenantView) localService(m *models.Microservice) *models.Microservice {
+	m.Name = v.local(m.Name)
+	m.DependsOn = v.localAll(m.DependsOn)
+	if m.LastResult != nil {
+		r := v.localResult(*m.LastResult)
+		m.LastResult = &r
+	}
+	return m
+}
+
+func (v tenantView) qualifyIncident(i models.Incident) models.Incident {
+	i.Service = v.qualify(i.Service)
+	i.ImpactedBy = v.qualifyAll(i.ImpactedBy)
+	if i.FirstError != nil {
+		r := v.qualifyResult(*i.FirstError)
+		i.FirstError = &r
+	}
+	return i
+}
+
+func (v tenantView) localIncident(i *models.Incident) *models.Incident {
+	i.Service = v.local(i.Service)
+	i.ImpactedBy = v.localAll(i.ImpactedBy)
+	if i.FirstError != nil {
+		r := v.localResult(*i.FirstError)
+		i.FirstError = &r
+	}
+	return i
+}
+
+func (v tenantView) localWindow(w models.MaintenanceWindow) models.MaintenanceWindow {
+	w.Services = v.localAll(w.Services)
+	return w
+}
+
+func (v tenantView) RegisterService(service models.Microservice) {
+	v.root.RegisterService(v.rootService(service))
+}
+
+// RegisterServiceLimited cuenta solo los servicios del tenant: son los que
+// comparten su prefijo en el store raíz.
+func (v tenantView) RegisterServiceLimited(service models.Microservice, max int) (bool, error) {
+	return v.root.RegisterServiceLimited(v.rootService(service), max)
+}
+
+// rootService devuelve el servicio con los nombres globales del tenant.
+func (v tenantView) rootService(service models.Microservice) models.Microservice {
+	m := service.Clone()
+	m.Tenant = v.tenant
+	m.Name = v.qualify(service.Name)
+	m.DependsOn = v.qualifyAll(service.DependsOn)
+	if m.LastResult != nil {
+		r := v.qualifyResult(*m.LastResult)
+		m.LastResult = &r
+	}
+	return m
+}
+
+func (v tenantView) GetAll() map[string]*models.Microservice {
+	all := make(map[string]*models.Microservice)
+	for name, service := range v.root.GetAll() {
+		if v.owns(name) {
+			all[v.local(name)] = v.localService(service)
+		}
+	}
+	return all
+}
+
+func (v tenantView) Get(name string) *models.Microservice {
+	if !models.ValidServiceName(name) {
+		return nil
+	}
+	service := v.root.Get(v.qualify(name))
+	if service == nil {
+		return nil
+	}
+	return v.localService(service)
+}
+
+func (v tenantView) DeleteService(name string) bool {
+	return models.ValidServiceName(name) && v.root.DeleteService(v.qualify(name))
+}
+
+func (v tenantView) SetPaused(name string, paused bool) bool {
+	return models.ValidServiceName(name) && v.root.SetPaused(v.qualify(name), paused)
+}
+
+func (v tenantView) UpdateService(name string, status string, lastCheck string) {
+	if models.ValidServiceName(name) {
+		v.root.UpdateService(v.qualify(name), status, lastCheck)
+	}
+}
+
+func (v tenantView) RecordResult(name string, result models.CheckResult) (string, bool) {
+	if !models.ValidServiceName(name) {
+		return "", false
+	}
+	return v.root.RecordResult(v.qualify(name), v.qualifyResult(result))
+}
+
+func (v tenantView) History(name string, since time.Time) []models.CheckResult {
+	if !models.ValidServiceName(name) {
+		return nil
+	}
+	history := v.root.History(v.qualify(name), since)
+	for i := range history {
+		history[i] = v.localResult(history[i])
+	}
+	return history
+}
+
+func (v tenantView) DailyStats(name string, since time.Time) []models.DailyStats {
+	if !models.ValidServiceName(name) {
+		return nil
+	}
+	return v.root.DailyStats(v.qualify(name), since)
+}
+
+func (v tenantView) CreateIncident(incident models.Incident) {
+	v.root.CreateIncident(v.qualifyIncident(incident))
+}
+
+func (v tenantView) UpdateIncident(id string, fn func(*models.Incident)) (*models.Incident, bool) {
+	// El servicio de un incidente no cambia, así que basta comprobar el
+	// tenant antes de actualizar
+	if v.GetIncident(id) == nil {
+		return nil, false
+	}
+	updated, ok := v.root.UpdateIncident(id, func(i *models.Incident) {
+		local := *i
+		fn(v.localIncident(&local))
+		*i = v.qualifyIncident(local)
+	})
+	if !ok {
+		return nil, false
+	}
+	return v.localIncident(updated), true
+}
+
+func (v tenantView) GetIncident(id string) *models.Incident {
+	incident := v.root.GetIncident(id)
+	if incident == nil || !v.owns(incident.Service) {
+		return nil
+	}
+	return v.localIncident(incident)
+}
+
+func (v tenantView) ListIncidents() []models.Incident {
+	var out []models.Incident
+	for _, incident := range v.root.ListIncidents() {
+		if v.owns(incident.Service) {
+			out = append(out, *v.localIncident(&incident))
+		}
+	}
+	return out
+}
+
+func (v tenantView) ActiveIncident(service string) *models.Incident {
+	if !models.ValidServiceName(service) {
+		return nil
+	}
+	incident := v.root.ActiveIncident(v.qualify(service))
+	if incident == nil {
+		return nil
+	}
+	return v.localIncident(incident)
+}
+
+func (v tenantView) SaveMaintenance(window models.MaintenanceWindow) {
+	w := window.Clone()
+	w.Tenant = v.tenant
+	w.Services = v.qualifyAll(window.Services)
+	v.root.SaveMaintenance(w)
+}
+
+func (v tenantView) DeleteMaintenance(id string) bool {
+	for _, w := range v.root.ListMaintenance() {
+		if w.ID == id && w.Tenant == v.tenant {
+			return v.root.DeleteMaintenance(id)
+		}
+	}
+	return false
+}
+
+func (v tenantView) ListMaintenance() []models.MaintenanceWindow {
+	var out []models.MaintenanceWindow
+	for _, w := range v.root.ListMaintenance() {
+		if w.Tenant == v.tenant {
+			out = append(out, v.localWindow(w))
+		}
+	}
+	return out
+}
+
+func (v tenantView) SaveAPIKey(key models.APIKey) {
+	key.Tenant = v.tenant
+	v.root.SaveAPIKey(key)
+}
+
+func (v tenantView) DeleteAPIKey(id string) bool {
+	for _, k := range v.root.ListAPIKeys() {
+		if k.ID == id && k.Tenant == v.tenant {
+			return v.root.DeleteAPIKey(id)
+		}
+	}
+	return false
+}
+
+func (v tenantView) ListAPIKeys() []models.APIKey {
+	var out []models.APIKey
+	for _, k := range v.root.ListAPIKeys() {
+		if k.Tenant == v.tenant {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func (v tenantView) APIKeyByHash(hash string) *models.APIKey {
+	key := v.root.APIKeyByHash(hash)
+	if key == nil || key.Tenant != v.tenant {
+		return nil
+	}
+	return key
+}
+
+func (v tenantView) AppendAudit(entry models.AuditEntry) {
+	entry.Tenant = v.tenant
+	v.root.AppendAudit(entry)
+}
+
+func (v tenantView) ListAudit() []models.AuditEntry {
+	var out []models.AuditEntry
+	for _, e := range v.root.ListAudit() {
+		if e.Tenant == v.tenant {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (v tenantView) Ping() error {
+	return v.root.Ping()
+}
+
+// Close no hace nada: la vista no es dueña del store compartido.
+func (v tenantView) Close() error {
+	return nil
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/config"
+	"health-check-app-micro/pkg/utils"
+)
+
+// Verifica los valores por defecto cuando no hay archivo, variables ni flags.
+func TestAppConfig_Defaults(t *testing.T) {
+	t.Setenv("APP_CONFIG", "")
+	t.Setenv("LISTEN_ADDR", "")
+	t.Setenv("STORE_BACKEND", "")
+	t.Setenv("CHECK_TIMEOUT", "")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listen != ":8080" || cfg.Store.Backend != "json" || time.Duration(cfg.Checker.Timeout) != 10*time.Second ||
+		time.Duration(cfg.Checker.Frequency) != 30*time.Second || cfg.LogLevel != "info" {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+}
+
+// Verifica la precedencia: flags > variables de entorno > archivo > defaults.
+func TestAppConfig_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeConfig(t, path, `
+listen: ":9000"
+logLevel: debug
+store:
+  backend: sqlite
+  path: /data/hc.db
+checker:
+  timeout: 3s
+  frequency: 1m
+notifier:
+  smtpHost: smtp.file
+  smtpPort: "25"
+`)
+	t.Setenv("APP_CONFIG", path)
+	t.Setenv("LISTEN_ADDR", ":9100")
+	t.Setenv("CHECK_TIMEOUT", "4s")
+	t.Setenv("SMTP_HOST", "smtp.env")
+	t.Setenv("STORE_BACKEND", "")
+
+	cfg, err := config.Load([]string{"-listen", ":9200", "-check-timeout=5s", "-config-strict"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listen != ":9200" || time.Duration(cfg.Checker.Timeout) != 5*time.Second {
+		t.Fatalf("flags must win: %+v", cfg)
+	}
+	if cfg.Notifier.SMTPHost != "smtp.env" || cfg.Notifier.SMTPPort != "25" {
+		t.Fatalf("env must win over the file: %+v", cfg.Notifier)
+	}
+	if cfg.Store.Backend != "sqlite" || cfg.Store.Path != "/data/hc.db" || time.Duration(cfg.Checker.Frequency) != time.Minute || cfg.LogLevel != "debug" {
+		t.Fatalf("file must win over defaults: %+v", cfg)
+	}
+	if !cfg.Services.Strict || time.Duration(cfg.Services.ReloadInterval) != 10*time.Second {
+		t.Fatalf("unexpected services config: %+v", cfg.Services)
+	}
+}
+
+// Verifica que se rechazan campos desconocidos del archivo y valores
+// inválidos, informando todos juntos.
+func TestAppConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("APP_CONFIG", "")
+	t.Setenv("LISTEN_ADDR", "")
+	t.Setenv("CHECK_TIMEOUT", "")
+
+	unknown := filepath.Join(dir, "app.json")
+	writeConfig(t, unknown, `{"listen": ":8080", "port": 8080}`)
+	if _, err := config.Load([]string{"-config", unknown}); err == nil || !strings.Contains(err.Error(), "port") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+
+	t.Setenv("CHECKER_WORKERS", "many")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "CHECKER_WORKERS") {
+		t.Fatalf("expected invalid env error, got %v", err)
+	}
+	t.Setenv("CHECKER_WORKERS", "")
+
+	_, err := config.Load([]string{"-listen", "8080", "-log-level", "loud", "-check-frequency", "5s", "-store-backend", "mongo"})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"listen", "logLevel", "checker.frequency", "store.backend"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+// Verifica las secciones de autenticación, límites e incidentes.
+func TestAppConfig_AuthAndLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	writeConfig(t, path, `
+[auth]
+enabled = true
+adminApiKey = "bootstrap"
+
+[auth.jwt]
+secret = "s3cret"
+roleScopes = "ROLE_OPS=read,register"
+
+[limits]
+maxServicesPerTenant = 5
+
+[incidents]
+repeatInterval = "1h"
+`)
+	t.Setenv("APP_CONFIG", path)
+	t.Setenv("RATE_LIMIT_RPS", "2.5")
+	t.Setenv("AUTH_PUBLIC_READ", "")
+
+	cfg, err := config.Load([]string{"-auth-public-read", "-max-body-bytes", "4096"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Auth.Enabled || !cfg.Auth.PublicRead || cfg.Auth.AdminAPIKey != "bootstrap" || !cfg.Auth.JWT.Enabled() {
+		t.Fatalf("unexpected auth config: %+v", cfg.Auth)
+	}
+	if cfg.RateLimit.RPS != 2.5 || cfg.RateLimit.Burst != 20 || cfg.RateLimit.IPRPS != 50 || cfg.RateLimit.IPBurst != 100 {
+		t.Fatalf("unexpected rate limit: %+v", cfg.RateLimit)
+	}
+	if cfg.Limits.MaxBodyBytes != 4096 || cfg.Limits.MaxServicesPerTenant != 5 {
+		t.Fatalf("unexpected limits: %+v", cfg.Limits)
+	}
+	if time.Duration(cfg.Incidents.RepeatInterval) != time.Hour {
+		t.Fatalf("unexpected repeat interval: %v", cfg.Incidents.RepeatInterval)
+	}
+
+	t.Setenv("APP_CONFIG", "")
+	t.Setenv("RATE_LIMIT_RPS", "")
+	_, err = config.Load([]string{"-rate-limit-rps", "-1", "-max-body-bytes", "0",
+		"-incident-repeat-interval", "-1m", "-jwt-role-scopes", "ops"})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"rateLimit.rps", "limits.maxBodyBytes", "incidents.repeatInterval", "auth.jwt.roleScopes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	t.Setenv("RATE_LIMIT_BURST", "lots")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "RATE_LIMIT_BURST") {
+		t.Fatalf("expected invalid env error, got %v", err)
+	}
+}
+
+func TestLogger_ParseLevel(t *testing.T) {
+	t.Parallel()
+
+	if l, err := utils.ParseLevel("WARN"); err != nil || l != utils.LevelWarn {
+		t.Fatalf("expected warn, got %v %v", l, err)
+	}
+	if _, err := utils.ParseLevel("verbose"); err == nil {
+		t.Fatal("expected unknown level error")
+	}
+}
+
+// Verifica que los mensajes por debajo del nivel configurado se descartan.
+func TestLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		utils.SetLevel(utils.LevelInfo)
+	})
+
+	utils.SetLevel(utils.LevelWarn)
+	utils.LogDebug("mensaje-debug")
+	utils.LogInfo("mensaje-info")
+	utils.LogWarn("mensaje-warn")
+	if out := buf.String(); strings.Contains(out, "mensaje-debug") || strings.Contains(out, "mensaje-info") || !strings.Contains(out, "[WARN] mensaje-warn") {
+		t.Fatalf("unexpected output at warn level: %q", out)
+	}
+
+	utils.SetLevel(utils.LevelDebug)
+	utils.LogDebug("mensaje-debug")
+	if !strings.Contains(buf.String(), "[DEBUG] mensaje-debug") {
+		t.Fatalf("expected debug message, got %q", buf.String())
+	}
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/audit"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que cada cambio hecho por la API queda en /audit con autor, IP,
+// acción y diferencias.
+func TestAudit_RecordsAPIChanges(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{{Name: "ops", Hash: auth.Hash("ops-secret"), Scopes: []string{"register"}}}); err != nil {
+		t.Fatal(err)
+	}
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "root-secret"})))
+
+	register := func(emails string) int {
+		body := `{"name":"api","endpoint":"http://127.0.0.1:1/health","frequency":3600,"emails":[` + emails + `]}`
+		return doAuth(router, "POST", "/register", "ops-secret", body).Code
+	}
+	if code := register(`"a@x.com"`); code != http.StatusCreated {
+		t.Fatalf("register: got %d", code)
+	}
+	if code := register(`"a@x.com"`); code != http.StatusCreated {
+		t.Fatalf("re-register: got %d", code)
+	}
+	if code := register(`"b@x.com"`); code != http.StatusCreated {
+		t.Fatalf("update: got %d", code)
+	}
+	if w := doAuth(router, "POST", "/services/api/pause", "ops-secret", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"paused":true`) {
+		t.Fatalf("pause: got %d %s", w.Code, w.Body.String())
+	}
+	if code := register(`"b@x.com"`); code != http.StatusCreated || storage.Get("api").Paused != true {
+		t.Fatal("re-registering must keep the service paused")
+	}
+	doAuth(router, "POST", "/services/api/resume", "ops-secret", "")
+
+	storage.CreateIncident(models.Incident{ID: "inc_audit", Service: "api", Status: models.IncidentOpen, StartedAt: time.Now()})
+	if w := doAuth(router, "POST", "/incidents/inc_audit/ack", "ops-secret", `{"note":"mirando"}`); w.Code != http.StatusOK {
+		t.Fatalf("ack: got %d", w.Code)
+	}
+	w := doAuth(router, "POST", "/maintenance", "ops-secret", `{"services":["api"],"durationMinutes":30}`)
+	var window models.MaintenanceWindow
+	_ = json.Unmarshal(w.Body.Bytes(), &window)
+	doAuth(router, "DELETE", "/maintenance/"+window.ID, "ops-secret", "")
+	if w := doAuth(router, "DELETE", "/services/api", "ops-secret", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d", w.Code)
+	}
+	if storage.Get("api") != nil {
+		t.Fatal("expected service deleted")
+	}
+
+	if w := doAuth(router, "GET", "/audit", "ops-secret", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("audit requires admin, got %d", w.Code)
+	}
+	w = doAuth(router, "GET", "/audit", "root-secret", "")
+	var entries []models.AuditEntry
+	_ = json.Unmarshal(w.Body.Bytes(), &entries)
+
+	want := []string{
+		models.AuditServiceDelete, models.AuditMaintenanceDelete, models.AuditMaintenanceCreate,
+		models.AuditIncidentAck, models.AuditServiceResume, models.AuditServicePause,
+		models.AuditServiceUpdate, models.AuditServiceRegister,
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %s", len(want), w.Body.String())
+	}
+	for i, e := range entries {
+		if e.Action != want[i] || e.Actor != "ops" || e.SourceIP == "" || e.ID == "" {
+			t.Fatalf("entry %d: unexpected %+v", i, e)
+		}
+	}
+	update := entries[6]
+	if update.Target != "service:api" || len(update.Changes) != 1 || update.Changes[0].Field != "emails" ||
+		string(update.Changes[0].After) != `["b@x.com"]` {
+		t.Fatalf("unexpected update diff: %+v", update)
+	}
+	if del := entries[0]; del.Before == nil || del.After != nil || strings.Contains(string(del.Before), "lastCheck") {
+		t.Fatalf("delete must keep the configuration before: %s", del.Before)
+	}
+
+	w = doAuth(router, "GET", "/audit?action=service.pause&limit=5", "root-secret", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil || len(entries) != 1 {
+		t.Fatalf("filter by action: %s", w.Body.String())
+	}
+	if w := doAuth(router, "GET", "/t/team-a/audit", "root-secret", ""); w.Body.String() != "[]" {
+		t.Fatalf("tenant audit must not include default tenant entries, got %s", w.Body.String())
+	}
+}
+
+// Verifica que la auditoría persiste en ambos backends.
+func TestAudit_Persists(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []string{store.BackendJSON, store.BackendSQLite} {
+		path := filepath.Join(t.TempDir(), "data")
+		storage, err := store.Open(backend, path)
+		if err != nil {
+			t.Fatalf("%s: open: %v", backend, err)
+		}
+		before := &models.Microservice{Name: "api", Endpoint: "http://a", Frequency: 30}
+		after := &models.Microservice{Name: "api", Endpoint: "http://b", Frequency: 30, Status: "UP"}
+		entry := models.AuditEntry{Actor: "ana", Action: models.AuditServiceUpdate, Target: audit.Target(audit.TargetService, "api")}
+		if !audit.Record(storage, entry, audit.Service(before), audit.Service(after)) {
+			t.Fatalf("%s: expected entry recorded", backend)
+		}
+		if audit.Record(storage, entry, audit.Service(after), audit.Service(after)) {
+			t.Fatalf("%s: expected no entry without changes", backend)
+		}
+		storage.Close()
+
+		reopened, err := store.Open(backend, path)
+		if err != nil {
+			t.Fatalf("%s: reopen: %v", backend, err)
+		}
+		list := reopened.ListAudit()
+		if len(list) != 1 || list[0].Actor != "ana" || len(list[0].Changes) != 1 || list[0].Changes[0].Field != "endpoint" {
+			t.Fatalf("%s: unexpected audit %+v", backend, list)
+		}
+		reopened.Close()
+	}
+}
+
+// Verifica que las ventanas y API keys aplicadas desde el archivo de
+// configuración quedan en la auditoría con el actor config, sin el hash de
+// las keys y sin entradas cuando no cambian.
+func TestAudit_RecordsConfigChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "services-config.json")
+	write := func(scopes, maintenance string) {
+		writeConfig(t, path, `{
+		  "services": [{"name": "cfg-svc", "endpoint": "http://127.0.0.1:1/health", "frequency": 3600}],
+		  "maintenance": [`+maintenance+`],
+		  "apiKeys": [{"name": "ci", "tenant": "team-a", "hash": "`+auth.Hash("ci-secret")+`", "scopes": `+scopes+`}]
+		}`)
+	}
+	nightly := `{"id": "nightly", "services": ["cfg-svc"], "start": "2024-01-01T02:00:00Z", "end": "2024-01-01T03:00:00Z", "recurrence": "daily"}`
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	actions := func() []string {
+		var out []string
+		for _, e := range storage.ListAudit() {
+			if strings.HasPrefix(e.Target, audit.TargetMaintenance) || strings.HasPrefix(e.Target, audit.TargetAPIKey) {
+				if e.Actor != models.AuditActorConfig {
+					t.Fatalf("unexpected actor %q", e.Actor)
+				}
+				if strings.Contains(string(e.Before)+string(e.After), auth.Hash("ci-secret")) {
+					t.Fatalf("the key hash must not be audited: %+v", e)
+				}
+				out = append(out, e.Action)
+			}
+		}
+		return out
+	}
+
+	write(`["read"]`, nightly)
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(); len(got) != 2 {
+		t.Fatalf("expected maintenance and apikey creates, got %v", got)
+	}
+	team := store.ForTenant(storage, "team-a").ListAudit()
+	if len(team) != 1 || team[0].Action != models.AuditAPIKeyCreate {
+		t.Fatalf("the key must be audited in its tenant, got %+v", team)
+	}
+
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(); len(got) != 2 {
+		t.Fatalf("an unchanged config must not be audited again, got %v", got)
+	}
+
+	write(`["read","register"]`, "")
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	got := actions()
+	want := []string{models.AuditAPIKeyUpdate, models.AuditMaintenanceDelete}
+	if len(got) != 4 || !(got[0] == want[0] && got[1] == want[1] || got[0] == want[1] && got[1] == want[0]) {
+		t.Fatalf("expected apikey update and maintenance delete, got %v", got)
+	}
+}
+
+// Verifica que el backend JSON descarta la auditoría vencida y conserva un
+// máximo de entradas.
+func TestAudit_JSONRetention(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	old := time.Now().AddDate(0, 0, -store.AuditDays-1)
+	storage.AppendAudit(models.AuditEntry{ID: "old", Time: old, Actor: "ana", Action: models.AuditServiceUpdate})
+	storage.AppendAudit(models.AuditEntry{ID: "new", Time: time.Now(), Actor: "ana", Action: models.AuditServiceUpdate})
+	if list := storage.ListAudit(); len(list) != 1 || list[0].ID != "new" {
+		t.Fatalf("expected only the recent entry, got %+v", list)
+	}
+
+	for i := 0; i < 6000; i++ {
+		storage.AppendAudit(models.AuditEntry{ID: "e", Time: time.Now(), Actor: "ana", Action: models.AuditServiceUpdate})
+	}
+	if n := len(storage.ListAudit()); n >= 6000 {
+		t.Fatalf("the JSON audit trail must be capped, got %d entries", n)
+	}
+}
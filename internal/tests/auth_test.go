@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func doAuth(router *gin.Engine, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Verifica el flujo completo: la key bootstrap crea keys con scopes, y cada
+// endpoint exige el scope que le corresponde.
+func TestAuth_ScopesAndAdminEndpoints(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "svc", Endpoint: "http://a", Status: "UP"})
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "root-secret"})))
+
+	if w := doAuth(router, "GET", "/health", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without key, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/livez", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("probes must stay public, got %d", w.Code)
+	}
+
+	w := doAuth(router, "POST", "/admin/apikeys", "root-secret", `{"name":"dashboard","scopes":["read"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			ID     string `json:"id"`
+			Prefix string `json:"prefix"`
+		} `json:"apiKey"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	readKey := created.Key
+	if !strings.HasPrefix(readKey, created.APIKey.Prefix) {
+		t.Fatalf("expected prefix of the key, got %+v", created)
+	}
+
+	// Solo el hash queda guardado
+	for _, k := range storage.ListAPIKeys() {
+		if k.Hash != auth.Hash(readKey) || strings.Contains(k.Hash, readKey) {
+			t.Fatalf("expected only the hash stored, got %+v", k)
+		}
+	}
+
+	if w := doAuth(router, "GET", "/health", readKey, ""); w.Code != http.StatusOK {
+		t.Fatalf("read key: expected 200, got %d", w.Code)
+	}
+	body := `{"name":"x","endpoint":"http://127.0.0.1:1/health"}`
+	if w := doAuth(router, "POST", "/register", readKey, body); w.Code != http.StatusForbidden {
+		t.Fatalf("read key must not register, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/admin/apikeys", readKey, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("read key must not manage keys, got %d", w.Code)
+	}
+
+	w = doAuth(router, "GET", "/admin/apikeys", "root-secret", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), auth.Hash(readKey)) {
+		t.Fatalf("list must not expose hashes: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := doAuth(router, "DELETE", "/admin/apikeys/"+created.APIKey.ID, "root-secret", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", readKey, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked key: expected 401, got %d", w.Code)
+	}
+}
+
+// Verifica lecturas públicas, el header X-API-Key y las keys de configuración.
+func TestAuth_PublicReadAndConfigKeys(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{{Name: "ci", Hash: auth.Hash("ci-secret"), Scopes: []string{"register"}}}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{PublicRead: true})))
+
+	if w := doAuth(router, "GET", "/health", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("public read: expected 200, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", "wrong", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("invalid key must be rejected even on public reads, got %d", w.Code)
+	}
+	if w := doAuth(router, "POST", "/register", "", `{}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("writes need a key, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/register",
+		bytes.NewReader([]byte(`{"name":"x","endpoint":"http://127.0.0.1:1/health"}`)))
+	req.Header.Set("X-API-Key", "ci-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("config key: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{{Name: "bad", Hash: "nope", Scopes: []string{"read"}}}); err == nil {
+		t.Fatal("expected invalid hash to be rejected")
+	}
+}
+
+// Verifica que las keys persisten en ambos backends.
+func TestAuth_KeysPersist(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []string{store.BackendJSON, store.BackendSQLite} {
+		path := filepath.Join(t.TempDir(), "data")
+		storage, err := store.Open(backend, path)
+		if err != nil {
+			t.Fatalf("%s: open: %v", backend, err)
+		}
+		secret, key, err := auth.Create(storage, "ops", []string{models.ScopeAdmin})
+		if err != nil {
+			t.Fatalf("%s: create: %v", backend, err)
+		}
+		storage.Close()
+
+		reopened, err := store.Open(backend, path)
+		if err != nil {
+			t.Fatalf("%s: reopen: %v", backend, err)
+		}
+		got, ok := auth.New(reopened, auth.Config{}).Authenticate(secret)
+		if !ok || got.Name != key.Name || got.Method != auth.MethodAPIKey || !got.HasScope(models.ScopeRead) {
+			t.Fatalf("%s: expected persisted admin key, got %+v", backend, got)
+		}
+		reopened.Close()
+	}
+}
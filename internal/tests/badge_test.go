@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/badge"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica los badges de estado y uptime: SVG válido, texto, color y cache.
+func TestAPI_Badges(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "jwt-service", Endpoint: "http://a", Status: "UP"})
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		storage.RecordResult("jwt-service", models.CheckResult{Name: "jwt-service", Status: "UP", Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	storage.RecordResult("jwt-service", models.CheckResult{Name: "jwt-service", Status: "DOWN", Timestamp: now.AddDate(0, 0, -3)})
+	// Con datos desde el inicio de la ventana el badge no aclara la cobertura
+	storage.RegisterService(models.Microservice{Name: "veterano", Endpoint: "http://b", Status: "UP", Frequency: 60})
+	storage.RecordResult("veterano", models.CheckResult{Status: "UP", Timestamp: now.Add(-24*time.Hour + 10*time.Second)})
+	storage.RecordResult("veterano", models.CheckResult{Status: "UP", Timestamp: now})
+	router := api.SetupRouter(storage)
+
+	cases := []struct {
+		path     string
+		code     int
+		contains []string
+		cache    string
+	}{
+		{"/badge/jwt-service/status.svg", http.StatusOK, []string{"jwt-service", ">down<", badge.ColorRed}, "public, max-age=30"},
+		{"/badge/jwt-service/uptime.svg", http.StatusOK, []string{"uptime 24h", ">100% (2h)<", badge.ColorBrightGreen}, "public, max-age=300"},
+		{"/badge/jwt-service/uptime.svg?window=30d", http.StatusOK, []string{"uptime 30d", ">75.00% (4d)<", badge.ColorRed}, "public, max-age=300"},
+		{"/badge/veterano/uptime.svg", http.StatusOK, []string{">100%<"}, "public, max-age=300"},
+		{"/badge/nadie/status.svg", http.StatusNotFound, []string{"not found", badge.ColorGrey}, "no-cache"},
+		{"/badge/jwt-service/uptime.svg?window=xx", http.StatusBadRequest, []string{"invalid window"}, "no-cache"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.code, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/svg+xml") {
+			t.Fatalf("%s: unexpected content type %q", tc.path, ct)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != tc.cache {
+			t.Fatalf("%s: unexpected Cache-Control %q", tc.path, cc)
+		}
+		body := w.Body.String()
+		for _, want := range tc.contains {
+			if !strings.Contains(body, want) {
+				t.Fatalf("%s: expected %q in %s", tc.path, want, body)
+			}
+		}
+	}
+}
+
+// Verifica que el SVG es XML válido aun con caracteres especiales en el texto.
+func TestBadge_RenderEscapesText(t *testing.T) {
+	t.Parallel()
+
+	svg := badge.Render(`a<b&"c"`, "ok", badge.ColorGreen)
+	var doc struct {
+		XMLName xml.Name
+		Title   string `xml:"title"`
+	}
+	if err := xml.Unmarshal(svg, &doc); err != nil {
+		t.Fatalf("invalid SVG: %v\n%s", err, svg)
+	}
+	if doc.XMLName.Local != "svg" || doc.Title != `a<b&"c": ok` {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+}
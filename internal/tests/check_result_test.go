@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica la clasificación de errores de los checks y que el resultado queda en el store.
+func TestChecker_ResultErrorClasses(t *testing.T) {
+	t.Parallel()
+
+	assertion := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"status":"DOWN","detail":"db unreachable"}`))
+	}))
+	defer assertion.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer failing.Close()
+
+	// Puerto que se libera inmediatamente para provocar conexión rechazada
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedURL := "http://" + ln.Addr().String()
+	ln.Close()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+
+	cases := []struct {
+		name, endpoint, class string
+		httpStatus            int
+	}{
+		{"assertion", assertion.URL, models.ErrorClassAssertion, 200},
+		{"http", failing.URL, models.ErrorClassHTTP, 500},
+		{"refused", refusedURL, models.ErrorClassRefused, 0},
+	}
+	for _, c := range cases {
+		storage.RegisterService(models.Microservice{Name: c.name, Endpoint: c.endpoint, Frequency: 30, Status: "UNKNOWN"})
+		result, ok := checker.CheckNow(storage, c.name)
+		if !ok {
+			t.Fatalf("%s: service not found", c.name)
+		}
+		if result.Status != "DOWN" || result.ErrorClass != c.class || result.HTTPStatus != c.httpStatus {
+			t.Fatalf("%s: unexpected result %+v", c.name, result)
+		}
+		if result.Timestamp.IsZero() || result.Error == "" {
+			t.Fatalf("%s: expected timestamp and error message, got %+v", c.name, result)
+		}
+		stored := storage.Get(c.name)
+		if stored.LastResult == nil || stored.LastResult.ErrorClass != c.class {
+			t.Fatalf("%s: last result not stored: %+v", c.name, stored.LastResult)
+		}
+	}
+
+	if snippet := storage.Get("http").LastResult.ResponseSnippet; snippet != "boom" {
+		t.Fatalf("expected response snippet, got %q", snippet)
+	}
+}
+
+// Verifica que GET /health incluye el detalle del último check.
+// Verifica que una respuesta sin fin no retiene el check hasta el timeout:
+// se lee un resto acotado y la conexión se cierra.
+func TestChecker_EndlessBodyIsBounded(t *testing.T) {
+	t.Parallel()
+
+	endless := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+		padding := []byte(strings.Repeat(" ", 32<<10))
+		for {
+			if _, err := w.Write(padding); err != nil {
+				return
+			}
+		}
+	}))
+	defer endless.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "endless", Endpoint: endless.URL, Frequency: 30, Status: "UNKNOWN"})
+	start := time.Now()
+	result, ok := checker.CheckNow(storage, "endless")
+	if !ok {
+		t.Fatal("service not found")
+	}
+	if result.Status != "UP" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("check took %s draining the body", elapsed)
+	}
+}
+
+func TestAPI_HealthAll_IncludesLastResult(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+	storage.RegisterService(models.Microservice{Name: "detail", Endpoint: ts.URL, Frequency: 30})
+	checker.CheckNow(storage, "detail")
+
+	router := api.SetupRouter(storage)
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var services map[string]*models.Microservice
+	if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	result := services["detail"].LastResult
+	if result == nil || result.Status != "UP" || result.HTTPStatus != 200 {
+		t.Fatalf("expected last result in response, got %+v", result)
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que un archivo YAML resuelve variables de entorno, valores por
+// defecto y secretos en archivos, y aplica el bloque defaults.
+func TestConfigFile_YAMLInterpolationAndDefaults(t *testing.T) {
+	t.Setenv("HC_GATEWAY_HOST", "gateway.internal")
+	t.Setenv("HC_FREQUENCY", "45")
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "smtp_to")
+	if err := os.WriteFile(secret, []byte("ops@x.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "services-config.yaml")
+	writeConfig(t, path, `
+defaults:
+  frequency: ${HC_FREQUENCY}
+  emails: ["${file:`+secret+`}"]
+  tags: [env:prod]
+services:
+  - name: gateway
+    endpoint: http://${HC_GATEWAY_HOST}:8085/health
+    tags: [team:core]
+  - name: perfil
+    endpoint: http://${HC_PERFIL_HOST:-perfil}:8084/health
+    frequency: 60
+    emails: [perfil@x.com]
+    dependsOn: [gateway]
+    tags: ["cost:$$5"]
+`)
+
+	cfg, err := registry.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Services) != 2 {
+		t.Fatalf("expected 2 services, got %+v", cfg.Services)
+	}
+	gateway, perfil := cfg.Services[0], cfg.Services[1]
+	if gateway.Endpoint != "http://gateway.internal:8085/health" || gateway.Frequency != 45 {
+		t.Fatalf("unexpected gateway: %+v", gateway)
+	}
+	if len(gateway.Emails) != 1 || gateway.Emails[0] != "ops@x.com" {
+		t.Fatalf("expected secret email, got %v", gateway.Emails)
+	}
+	if strings.Join(gateway.Tags, ",") != "team:core,env:prod" {
+		t.Fatalf("expected default tags appended, got %v", gateway.Tags)
+	}
+	if perfil.Endpoint != "http://perfil:8084/health" || perfil.Frequency != 60 || perfil.Emails[0] != "perfil@x.com" || perfil.Tags[0] != "cost:$5" {
+		t.Fatalf("service values must win over defaults: %+v", perfil)
+	}
+
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	if svc := storage.Get("perfil"); svc == nil || len(svc.DependsOn) != 1 {
+		t.Fatalf("expected perfil registered from YAML, got %+v", svc)
+	}
+}
+
+// Verifica que un archivo TOML se lee igual que el JSON.
+func TestConfigFile_TOML(t *testing.T) {
+	t.Setenv("HC_TOML_PORT", "9090")
+	path := filepath.Join(t.TempDir(), "services-config.toml")
+	writeConfig(t, path, `
+[defaults]
+frequency = 20
+
+[[services]]
+name = "api"
+endpoint = "http://api:${HC_TOML_PORT}/health"
+critical = true
+
+[[maintenance]]
+id = "nightly"
+services = ["api"]
+start = "2024-01-01T02:00:00Z"
+end = "2024-01-01T03:00:00Z"
+recurrence = "daily"
+`)
+	cfg, err := registry.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Services) != 1 || cfg.Services[0].Endpoint != "http://api:9090/health" || !cfg.Services[0].Critical || cfg.Services[0].Frequency != 20 {
+		t.Fatalf("unexpected services: %+v", cfg.Services)
+	}
+	if len(cfg.Maintenance) != 1 || cfg.Maintenance[0].ID != "nightly" {
+		t.Fatalf("unexpected maintenance: %+v", cfg.Maintenance)
+	}
+}
+
+// Verifica que una referencia sola solo se convierte a número o booleano
+// cuando el campo de destino lo es; en los campos de texto queda como texto.
+func TestConfigFile_InterpolationKeepsStringFields(t *testing.T) {
+	t.Setenv("HC_SVC_NAME", "1234")
+	t.Setenv("HC_SVC_TAG", "true")
+	t.Setenv("HC_SVC_FREQUENCY", "15")
+	t.Setenv("HC_SVC_CRITICAL", "true")
+	path := filepath.Join(t.TempDir(), "services-config.yaml")
+	writeConfig(t, path, `
+services:
+  - name: "${HC_SVC_NAME}"
+    endpoint: http://api/health
+    frequency: ${HC_SVC_FREQUENCY}
+    critical: ${HC_SVC_CRITICAL}
+    tags: ["${HC_SVC_TAG}"]
+`)
+	cfg, err := registry.LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	svc := cfg.Services[0]
+	if svc.Name != "1234" || len(svc.Tags) != 1 || svc.Tags[0] != "true" {
+		t.Fatalf("string fields must stay strings: %+v", svc)
+	}
+	if svc.Frequency != 15 || !svc.Critical {
+		t.Fatalf("numeric and boolean fields must be converted: %+v", svc)
+	}
+}
+
+// Verifica que una variable sin definir ni valor por defecto es un error.
+func TestConfigFile_UndefinedVariable(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services-config.json")
+	writeConfig(t, path, `[{"name": "api", "endpoint": "http://${HC_UNDEFINED_HOST_FOR_TEST}/health"}]`)
+	if _, err := registry.LoadConfigFile(path); err == nil || !strings.Contains(err.Error(), "HC_UNDEFINED_HOST_FOR_TEST") {
+		t.Fatalf("expected undefined variable error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+)
+
+// problemsOf carga el archivo y devuelve sus problemas como "línea ruta: mensaje".
+func problemsOf(t *testing.T, path string) []registry.Problem {
+	t.Helper()
+	_, err := registry.LoadConfigFile(path)
+	var invalid *registry.ConfigError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected *ConfigError, got %v", err)
+	}
+	return invalid.Problems
+}
+
+// findProblem busca el problema de la ruta indicada.
+func findProblem(t *testing.T, problems []registry.Problem, path, contains string) registry.Problem {
+	t.Helper()
+	for _, p := range problems {
+		if p.Path == path && strings.Contains(p.Message, contains) {
+			return p
+		}
+	}
+	t.Fatalf("no problem %q at %s in %+v", contains, path, problems)
+	return registry.Problem{}
+}
+
+// Verifica que se informan todos los problemas del archivo JSON, cada uno
+// con su línea.
+func TestConfigValidate_ReportsAllProblemsWithLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services-config.json")
+	writeConfig(t, path, `{
+  "services": [
+    {"name": "api", "endpoint": "http://api/health", "frequency": 30},
+    {"name": "web", "endpoint": "htp:/web", "frequency": 5,
+     "emails": ["ops@x.com", "not-an-email"]},
+    {"name": "api", "endpoint": "http://api2/health"},
+    {"name": "a", "endpoint": "http://a", "dependsOn": ["b"], "timeout": 3},
+    {"name": "b", "endpoint": "http://b", "dependsOn": ["a"]}
+  ],
+  "notificationRoutes": [{"tag": "team:x", "emails": ["bad@"]}]
+}`)
+	problems := problemsOf(t, path)
+
+	checks := []struct {
+		path, contains string
+		line           int
+	}{
+		{"services[1].endpoint", "URL inválida", 4},
+		{"services[1].frequency", "entre 10 y 86400", 4},
+		{"services[1].emails[1]", "email inválido", 5},
+		{"services[2].name", "línea 3", 6},
+		{"services[3].timeout", "campo desconocido", 7},
+		{"services[3].dependsOn", "circular", 7},
+		{"notificationRoutes[0].emails[0]", "email inválido", 10},
+	}
+	for _, c := range checks {
+		p := findProblem(t, problems, c.path, c.contains)
+		if p.Line != c.line || p.File != path {
+			t.Errorf("%s: expected line %d, got %s", c.path, c.line, p)
+		}
+	}
+	if len(problems) != len(checks) {
+		t.Fatalf("expected %d problems, got %+v", len(checks), problems)
+	}
+}
+
+// Verifica las líneas en YAML y TOML, y los errores de sintaxis y de tipo.
+func TestConfigValidate_YAMLAndTOMLLines(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "services-config.yaml")
+	writeConfig(t, yamlPath, `services:
+  - name: api
+    endpoint: http://api/health
+  - name: web
+    endpoint: ftp://web
+    frequency: often
+`)
+	problems := problemsOf(t, yamlPath)
+	if p := findProblem(t, problems, "services[1].frequency", "entero"); p.Line != 6 {
+		t.Fatalf("yaml: expected line 6, got %s", p)
+	}
+
+	tomlPath := filepath.Join(dir, "services-config.toml")
+	writeConfig(t, tomlPath, `[[services]]
+name = "api"
+endpoint = "http://api/health"
+
+[[services]]
+name = "web"
+endpoint = "http://web"
+emails = ["ops@x.com", "nope"]
+`)
+	problems = problemsOf(t, tomlPath)
+	if p := findProblem(t, problems, "services[1].emails[1]", "email inválido"); p.Line != 8 {
+		t.Fatalf("toml: expected line 8, got %s", p)
+	}
+
+	brokenPath := filepath.Join(dir, "broken.json")
+	writeConfig(t, brokenPath, "[\n  {\"name\": \"api\",\n  }\n]")
+	problems = problemsOf(t, brokenPath)
+	if len(problems) != 1 || problems[0].Line != 3 {
+		t.Fatalf("syntax error: expected line 3, got %+v", problems)
+	}
+}
+
+// Verifica que en modo estricto una configuración inválida o ausente impide
+// arrancar, y que sin él se registran las entradas válidas sin recurrir a
+// los servicios por defecto.
+func TestConfigValidate_StrictMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "services-config.json")
+	writeConfig(t, path, `[
+	  {"name": "api", "endpoint": "http://127.0.0.1:1/api", "frequency": 3600},
+	  {"name": "bad", "endpoint": "nope"}
+	]`)
+
+	registry.StrictConfig = true
+	defer func() { registry.StrictConfig = false }()
+	strict := store.NewStoreWithPath(filepath.Join(dir, "strict.json"))
+	if err := registry.AutoRegisterServices(strict, path); err == nil {
+		t.Fatal("strict mode must reject an invalid config")
+	}
+	if err := registry.AutoRegisterServices(strict, filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("strict mode must reject a missing config")
+	}
+	if len(strict.GetAll()) != 0 {
+		t.Fatalf("strict mode must not register anything, got %+v", strict.GetAll())
+	}
+
+	registry.StrictConfig = false
+	lenient := store.NewStoreWithPath(filepath.Join(dir, "lenient.json"))
+	if err := registry.AutoRegisterServices(lenient, path); err != nil {
+		t.Fatal(err)
+	}
+	if all := lenient.GetAll(); len(all) != 1 || all["api"] == nil {
+		t.Fatalf("expected only the valid service, got %+v", all)
+	}
+}
+
+// Verifica que los servicios por defecto usan la frecuencia configurada en
+// checker.frequency.
+func TestConfigValidate_DefaultServicesFrequency(t *testing.T) {
+	models.DefaultFrequency = 3600
+	defer func() { models.DefaultFrequency = 30 }()
+
+	dir := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	if err := registry.AutoRegisterServices(storage, filepath.Join(dir, "missing.json")); err != nil {
+		t.Fatal(err)
+	}
+	all := storage.GetAll()
+	if len(all) == 0 {
+		t.Fatal("expected the default services")
+	}
+	for name, service := range all {
+		if service.Frequency != 3600 {
+			t.Errorf("%s: expected frequency 3600, got %d", name, service.Frequency)
+		}
+	}
+}
+
+// Verifica que sin modo estricto no se registran los servicios que el
+// validador señaló y que de un repetido vale la primera definición.
+func TestConfigValidate_LenientSkipsReportedServices(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "services-config.json")
+	writeConfig(t, path, `[
+	  {"name": "api", "endpoint": "http://127.0.0.1:1/first", "frequency": 3600},
+	  {"name": "mail", "endpoint": "http://127.0.0.1:1/mail", "frequency": 3600, "emails": ["not-an-email"]},
+	  {"name": "slow", "endpoint": "http://127.0.0.1:1/slow", "frequency": 99999999},
+	  {"name": "api", "endpoint": "http://127.0.0.1:1/second", "frequency": 3600}
+	]`)
+
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	all := storage.GetAll()
+	if len(all) != 1 || all["api"] == nil || all["api"].Endpoint != "http://127.0.0.1:1/first" {
+		t.Fatalf("expected only the first api definition, got %+v", all)
+	}
+}
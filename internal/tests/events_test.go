@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/events"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica el buffer circular, el filtro y la reanudación desde un ID.
+func TestEvents_BrokerBufferAndResume(t *testing.T) {
+	t.Parallel()
+
+	broker := events.NewBroker(3)
+	for i := 0; i < 5; i++ {
+		svc := "a"
+		if i%2 == 1 {
+			svc = "b"
+		}
+		broker.Publish(events.Event{Type: events.TypeCheck, Service: svc})
+	}
+
+	// Solo quedan los IDs 3, 4 y 5
+	_, backlog := broker.Subscribe(events.Filter{}, 1)
+	if len(backlog) != 3 || backlog[0].ID != 3 || backlog[2].ID != 5 {
+		t.Fatalf("unexpected backlog: %+v", backlog)
+	}
+
+	sub, backlog := broker.Subscribe(events.Filter{Services: []string{"a"}}, 3)
+	if len(backlog) != 1 || backlog[0].ID != 5 {
+		t.Fatalf("expected only event 5 for service a, got %+v", backlog)
+	}
+
+	broker.Publish(events.Event{Type: events.TypeCheck, Service: "b"})
+	broker.Publish(events.Event{Type: events.TypeTransition, Service: "a"})
+	select {
+	case e := <-sub.C:
+		if e.ID != 7 || e.Service != "a" {
+			t.Fatalf("unexpected live event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a live event")
+	}
+
+	broker.Unsubscribe(sub)
+	if _, ok := <-sub.C; ok {
+		t.Fatal("expected channel closed after unsubscribe")
+	}
+}
+
+// readEvent lee eventos SSE del stream hasta encontrar uno del tipo
+// indicado, y devuelve su ID y datos.
+func readEvent(t *testing.T, scanner *bufio.Scanner, eventType string) (string, events.Event) {
+	t.Helper()
+	var id, name, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "":
+			if name == eventType {
+				var e events.Event
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					t.Fatalf("invalid event data %q: %v", data, err)
+				}
+				return id, e
+			}
+			id, name, data = "", "", ""
+		}
+	}
+	t.Fatalf("stream ended without %s event: %v", eventType, scanner.Err())
+	return "", events.Event{}
+}
+
+// Verifica que GET /events transmite los checks y cambios de estado del
+// servicio filtrado, y que se puede reanudar con Last-Event-ID.
+func TestAPI_EventsStream(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "sse-svc", Endpoint: target.URL, Frequency: 30, Status: "UP"})
+	srv := httptest.NewServer(api.SetupRouter(storage))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/events?service=sse-svc", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	stream := bufio.NewScanner(resp.Body)
+	checker.CheckNow(storage, "sse-svc")
+	checkID, check := readEvent(t, stream, events.TypeCheck)
+	if check.Service != "sse-svc" || check.Result == nil || check.Result.Status != "DOWN" {
+		t.Fatalf("unexpected check event: %+v", check)
+	}
+	_, transition := readEvent(t, stream, events.TypeTransition)
+	if transition.From != "UP" || transition.To != "DOWN" {
+		t.Fatalf("unexpected transition event: %+v", transition)
+	}
+
+	// Reanudar desde el check: el cambio de estado llega desde el buffer
+	req, _ = http.NewRequestWithContext(ctx, "GET", srv.URL+"/events?service=sse-svc", nil)
+	req.Header.Set("Last-Event-ID", checkID)
+	resumed, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+	defer resumed.Body.Close()
+	if _, e := readEvent(t, bufio.NewScanner(resumed.Body), events.TypeTransition); e.ID != transition.ID {
+		t.Fatalf("expected resumed transition %d, got %+v", transition.ID, e)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/graph"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que cuando cae una dependencia solo ella genera alerta y sus
+// dependientes quedan marcados como impactados.
+func TestGraph_RootCauseSuppressesDependentAlerts(t *testing.T) {
+	t.Parallel()
+
+	var jwtUp, gatewayUp atomic.Bool
+	jwtUp.Store(true)
+	gatewayUp.Store(true)
+	handler := func(up *atomic.Bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if up.Load() {
+				w.WriteHeader(200)
+				return
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+	jwt := httptest.NewServer(handler(&jwtUp))
+	defer jwt.Close()
+	gateway := httptest.NewServer(handler(&gatewayUp))
+	defer gateway.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "jwt-service", Endpoint: jwt.URL, Frequency: 30, Status: "UP"})
+	storage.RegisterService(models.Microservice{Name: "api-gateway", Endpoint: gateway.URL, Frequency: 30, Status: "UP",
+		DependsOn: []string{"jwt-service"}})
+
+	// El gateway falla primero: antes de alertar se verifica jwt-service,
+	// que resulta ser la causa raíz
+	jwtUp.Store(false)
+	gatewayUp.Store(false)
+	result, _ := checker.CheckNow(storage, "api-gateway")
+	if len(result.ImpactedBy) != 1 || result.ImpactedBy[0] != "jwt-service" {
+		t.Fatalf("expected gateway impacted by jwt-service, got %+v", result.ImpactedBy)
+	}
+
+	root := storage.ActiveIncident("jwt-service")
+	if root == nil || len(root.Notifications) != 1 || root.IsImpact() {
+		t.Fatalf("expected a notified root incident for jwt-service, got %+v", root)
+	}
+	impacted := storage.ActiveIncident("api-gateway")
+	if impacted == nil || !impacted.IsImpact() || len(impacted.Notifications) != 0 {
+		t.Fatalf("expected a silent impact incident for api-gateway, got %+v", impacted)
+	}
+
+	router := api.SetupRouter(storage)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/graph", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var g graph.Graph
+	if err := json.Unmarshal(w.Body.Bytes(), &g); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(g.Edges) != 1 || g.Edges[0] != (graph.Edge{From: "api-gateway", To: "jwt-service"}) {
+		t.Fatalf("unexpected edges: %+v", g.Edges)
+	}
+	nodes := map[string]graph.Node{}
+	for _, n := range g.Nodes {
+		nodes[n.Name] = n
+	}
+	if !nodes["jwt-service"].RootCause || nodes["jwt-service"].Status != "DOWN" {
+		t.Fatalf("expected jwt-service as DOWN root cause, got %+v", nodes["jwt-service"])
+	}
+	if nodes["api-gateway"].RootCause || len(nodes["api-gateway"].ImpactedBy) != 1 {
+		t.Fatalf("expected api-gateway impacted, got %+v", nodes["api-gateway"])
+	}
+
+	// jwt-service se recupera pero el gateway sigue caído: ahora la alerta es suya
+	jwtUp.Store(true)
+	checker.CheckNow(storage, "jwt-service")
+	checker.CheckNow(storage, "api-gateway")
+	escalated := storage.ActiveIncident("api-gateway")
+	if escalated == nil || escalated.IsImpact() || len(escalated.Notifications) != 1 {
+		t.Fatalf("expected escalated incident with its own alert, got %+v", escalated)
+	}
+}
+
+// Verifica que el registro rechaza dependencias circulares.
+func TestGraph_RegisterRejectsCycle(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "a", Endpoint: "http://a", Frequency: 30, DependsOn: []string{"b"}})
+	router := api.SetupRouter(storage)
+
+	body := []byte(`{"name":"b","endpoint":"http://127.0.0.1:1/health","frequency":30,"dependsOn":["a"]}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/register", bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for dependency cycle, got %d %s", w.Code, w.Body.String())
+	}
+	if storage.Get("b") != nil {
+		t.Fatal("service with cyclic dependency must not be registered")
+	}
+}
+
+func TestGraph_FindCycle(t *testing.T) {
+	t.Parallel()
+
+	if c := graph.FindCycle(map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil}); c != nil {
+		t.Fatalf("expected no cycle, got %v", c)
+	}
+	c := graph.FindCycle(map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}})
+	if len(c) != 4 || c[0] != c[len(c)-1] {
+		t.Fatalf("expected closed cycle of three services, got %v", c)
+	}
+	if c := graph.FindCycle(map[string][]string{"self": {"self"}}); len(c) != 2 {
+		t.Fatalf("expected self-dependency cycle, got %v", c)
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/incidents"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Recorre el ciclo de vida de un incidente (apertura, reconocimiento, nota y
+// cierre) con ambos backends de almacenamiento.
+func TestIncidents_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []string{store.BackendJSON, store.BackendSQLite} {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			var healthy atomic.Bool
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if healthy.Load() {
+					w.WriteHeader(200)
+					return
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer ts.Close()
+
+			storage, err := store.Open(backend, filepath.Join(t.TempDir(), "data"))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			defer storage.Close()
+			storage.RegisterService(models.Microservice{Name: "flaky", Endpoint: ts.URL, Frequency: 30, Emails: []string{"oncall@x.com"}, Status: "UP"})
+			router := api.SetupRouter(storage)
+
+			checker.CheckNow(storage, "flaky")
+			active := storage.ActiveIncident("flaky")
+			if active == nil {
+				t.Fatal("expected an open incident after DOWN transition")
+			}
+			if active.FirstError == nil || active.FirstError.HTTPStatus != http.StatusServiceUnavailable {
+				t.Fatalf("expected first error recorded, got %+v", active.FirstError)
+			}
+			if len(active.Notifications) != 1 || active.Notifications[0].Kind != incidents.NotificationDown {
+				t.Fatalf("expected down notification recorded, got %+v", active.Notifications)
+			}
+
+			// Sin reconocer, la alerta se repite pasado el intervalo
+			later := time.Now().Add(incidents.RepeatInterval + time.Minute)
+			if incidents.DueForRepeat(storage, "flaky", later) == nil {
+				t.Fatal("expected repeat notification to be due")
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/incidents/"+active.ID+"/ack",
+				bytes.NewReader([]byte(`{"by":"ana","note":"mirando logs"}`))))
+			if w.Code != http.StatusOK {
+				t.Fatalf("ack: expected 200, got %d %s", w.Code, w.Body.String())
+			}
+			if incidents.DueForRepeat(storage, "flaky", later) != nil {
+				t.Fatal("acknowledged incident must not repeat notifications")
+			}
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/incidents/"+active.ID+"/notes",
+				bytes.NewReader([]byte(`{"author":"ana","text":"reiniciado el pod"}`))))
+			if w.Code != http.StatusCreated {
+				t.Fatalf("note: expected 201, got %d", w.Code)
+			}
+
+			healthy.Store(true)
+			checker.CheckNow(storage, "flaky")
+			if storage.ActiveIncident("flaky") != nil {
+				t.Fatal("expected incident to be closed on recovery")
+			}
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/incidents/"+active.ID, nil))
+			var got models.Incident
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if got.Status != models.IncidentResolved || got.ResolvedAt == nil {
+				t.Fatalf("expected resolved incident, got %+v", got)
+			}
+			if !got.Acknowledged || got.AcknowledgedBy != "ana" || len(got.Notes) != 2 {
+				t.Fatalf("unexpected ack/notes: %+v", got)
+			}
+			if got.Notifications[len(got.Notifications)-1].Kind != incidents.NotificationRecovery {
+				t.Fatalf("expected recovery notification last, got %+v", got.Notifications)
+			}
+
+			// Reconocer un incidente resuelto es un conflicto
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/incidents/"+active.ID+"/ack", nil))
+			if w.Code != http.StatusConflict {
+				t.Fatalf("expected 409 acking resolved incident, got %d", w.Code)
+			}
+		})
+	}
+}
+
+// Verifica el listado filtrado y el 404 de incidentes inexistentes.
+func TestAPI_Incidents_ListAndNotFound(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	now := time.Now()
+	storage.CreateIncident(models.Incident{ID: "a", Service: "s1", Status: models.IncidentOpen, StartedAt: now})
+	storage.CreateIncident(models.Incident{ID: "b", Service: "s2", Status: models.IncidentResolved, StartedAt: now.Add(-time.Hour)})
+	router := api.SetupRouter(storage)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/incidents?status=open", nil))
+	var list []models.Incident
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "a" {
+		t.Fatalf("unexpected filtered list: %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/incidents/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
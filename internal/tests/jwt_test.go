@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/store"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksServer publica la clave pública RSA como JWKS y cuenta las descargas.
+func jwksServer(t *testing.T, pub *rsa.PublicKey, kid string, hits *int64) *httptest.Server {
+	t.Helper()
+	doc := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(hits, 1)
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Verifica la validación con JWKS: firma, expiración, emisor y roles.
+func TestJWT_JWKSRolesToScopes(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits int64
+	jwks := jwksServer(t, &key.PublicKey, "k1", &hits)
+	defer jwks.Close()
+
+	validator, err := auth.NewJWTValidator(auth.JWTConfig{JWKSURL: jwks.URL, Issuer: "jwt-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{JWT: validator})))
+
+	exp := time.Now().Add(time.Hour).Unix()
+	register := signRS256(t, key, "k1", jwt.MapClaims{"sub": "ci", "iss": "jwt-service", "exp": exp, "roles": []string{"register"}})
+	reader := signRS256(t, key, "k1", jwt.MapClaims{"sub": "dash", "iss": "jwt-service", "exp": exp, "roles": "read"})
+	expired := signRS256(t, key, "k1", jwt.MapClaims{"sub": "old", "iss": "jwt-service", "exp": time.Now().Add(-time.Hour).Unix(), "roles": []string{"admin"}})
+	wrongIss := signRS256(t, key, "k1", jwt.MapClaims{"sub": "x", "iss": "otro", "exp": exp, "roles": []string{"admin"}})
+	noRoles := signRS256(t, key, "k1", jwt.MapClaims{"sub": "x", "iss": "jwt-service", "exp": exp})
+	hmac, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "x", "iss": "jwt-service", "exp": exp, "roles": []string{"admin"}}).SignedString([]byte("secret"))
+
+	body := `{"name":"jwt-svc","endpoint":"http://127.0.0.1:1/health"}`
+	cases := []struct {
+		name, method, path, token, body string
+		want                            int
+	}{
+		{"register role registers", "POST", "/register", register, body, http.StatusCreated},
+		{"register role reads", "GET", "/health", register, "", http.StatusForbidden},
+		{"read role reads", "GET", "/health", reader, "", http.StatusOK},
+		{"read role cannot register", "POST", "/register", reader, body, http.StatusForbidden},
+		{"expired token", "GET", "/health", expired, "", http.StatusUnauthorized},
+		{"wrong issuer", "GET", "/health", wrongIss, "", http.StatusUnauthorized},
+		{"token without roles", "GET", "/health", noRoles, "", http.StatusUnauthorized},
+		{"hmac not enabled", "GET", "/health", hmac, "", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if w := doAuth(router, tc.method, tc.path, tc.token, tc.body); w.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if n := atomic.LoadInt64(&hits); n != 1 {
+		t.Fatalf("expected JWKS fetched once and cached, got %d fetches", n)
+	}
+}
+
+// Verifica el secreto compartido con un claim de roles anidado y mapeo de roles.
+func TestJWT_SharedSecretNestedRoles(t *testing.T) {
+	t.Parallel()
+
+	roles, err := auth.ParseRoleScopes("ROLE_ADMIN=admin; ROLE_USER=read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := auth.NewJWTValidator(auth.JWTConfig{
+		Secret:     "s3cret",
+		Audience:   "health-check",
+		RolesClaim: "realm_access.roles",
+		RoleScopes: roles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{JWT: validator})))
+
+	sign := func(claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("s3cret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	admin := sign(jwt.MapClaims{"preferred_username": "ana", "aud": "health-check", "exp": exp,
+		"realm_access": map[string]any{"roles": []string{"ROLE_ADMIN"}}})
+	user := sign(jwt.MapClaims{"sub": "u", "aud": "health-check", "exp": exp,
+		"realm_access": map[string]any{"roles": []string{"ROLE_USER"}}})
+	otherAud := sign(jwt.MapClaims{"sub": "u", "aud": "otra-app", "exp": exp,
+		"realm_access": map[string]any{"roles": []string{"ROLE_ADMIN"}}})
+
+	if w := doAuth(router, "GET", "/admin/apikeys", admin, ""); w.Code != http.StatusOK {
+		t.Fatalf("admin: expected 200, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/admin/apikeys", user, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("user: expected 403, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", otherAud, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("other audience: expected 401, got %d", w.Code)
+	}
+
+	// El tenant del token limita dónde opera; sin claim es el tenant por defecto
+	teamA := sign(jwt.MapClaims{"sub": "a", "aud": "health-check", "exp": exp, "tenant": "team-a",
+		"realm_access": map[string]any{"roles": []string{"ROLE_ADMIN"}}})
+	badTenant := sign(jwt.MapClaims{"sub": "a", "aud": "health-check", "exp": exp, "tenant": "Team_A",
+		"realm_access": map[string]any{"roles": []string{"ROLE_ADMIN"}}})
+	if w := doAuth(router, "GET", "/t/team-a/health", teamA, ""); w.Code != http.StatusOK {
+		t.Fatalf("team-a token: expected 200, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", teamA, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("team-a token on default tenant: expected 403, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/t/team-a/health", admin, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("token without tenant on team-a: expected 403, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", badTenant, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("invalid tenant claim: expected 401, got %d", w.Code)
+	}
+
+	if _, err := auth.ParseRoleScopes("ROLE_X=superuser"); err == nil {
+		t.Fatal("expected unknown scope in role mapping to fail")
+	}
+}
+
+// Verifica que con la copia local del JWKS se validan tokens aunque
+// jwt-service no responda.
+func TestJWT_OfflineJWKSCache(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits int64
+	jwks := jwksServer(t, &key.PublicKey, "k1", &hits)
+	cachePath := filepath.Join(t.TempDir(), "jwks.json")
+	token := signRS256(t, key, "k1", jwt.MapClaims{"sub": "ci", "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"read"}})
+
+	online, _ := auth.NewJWTValidator(auth.JWTConfig{JWKSURL: jwks.URL, JWKSCachePath: cachePath})
+	if _, err := online.Validate(token); err != nil {
+		t.Fatalf("online validation: %v", err)
+	}
+	url := jwks.URL
+	jwks.Close()
+
+	offline, _ := auth.NewJWTValidator(auth.JWTConfig{JWKSURL: url, JWKSCachePath: cachePath})
+	p, err := offline.Validate(token)
+	if err != nil {
+		t.Fatalf("offline validation with cached JWKS: %v", err)
+	}
+	if p.Name != "ci" || p.Method != auth.MethodJWT {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/ratelimit"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que el limiter deja pasar la ráfaga y luego indica cuánto esperar.
+func TestRateLimiter_BurstThenWait(t *testing.T) {
+	t.Parallel()
+
+	limiter := ratelimit.New(1, 3)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d within burst was rejected", i)
+		}
+	}
+	ok, wait := limiter.Allow("a")
+	if ok || wait <= 0 {
+		t.Fatalf("expected rejection with positive wait, got %v %v", ok, wait)
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Fatal("another client must have its own bucket")
+	}
+}
+
+// Verifica que la API responde 429 con Retry-After por cliente, separando
+// las API keys de las IPs, y que /status y las probes no se limitan.
+func TestRouter_RateLimitPerClient(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{{Name: "ops", Hash: auth.Hash("ops-secret"), Scopes: []string{"read"}}}); err != nil {
+		t.Fatal(err)
+	}
+	router := api.SetupRouter(storage,
+		api.WithAuth(auth.New(storage, auth.Config{PublicRead: true})),
+		api.WithRateLimit(0.01, 2),
+	)
+
+	for i := 0; i < 2; i++ {
+		if w := doAuth(router, "GET", "/health", "", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, w.Code)
+		}
+	}
+	w := doAuth(router, "GET", "/health", "", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry < 1 {
+		t.Fatalf("expected Retry-After in seconds, got %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := doAuth(router, "GET", "/health", "ops-secret", ""); w.Code != http.StatusOK {
+		t.Fatalf("API key must have its own quota, got %d", w.Code)
+	}
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.RemoteAddr = "198.51.100.7:4000"
+	other := httptest.NewRecorder()
+	router.ServeHTTP(other, req)
+	if other.Code != http.StatusOK {
+		t.Fatalf("another IP must have its own quota, got %d", other.Code)
+	}
+
+	for _, path := range []string{"/status", "/livez"} {
+		if w := doAuth(router, "GET", path, "", ""); w.Code == http.StatusTooManyRequests {
+			t.Fatalf("%s must not be rate limited", path)
+		}
+	}
+}
+
+// Verifica que el límite por IP se aplica antes de autenticar: los intentos
+// con credenciales inválidas también se frenan.
+func TestRouter_RateLimitBeforeAuth(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage,
+		api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "bootstrap"})),
+		api.WithRateLimit(100, 100),
+		api.WithIPRateLimit(0.01, 3),
+	)
+
+	for i := 0; i < 3; i++ {
+		if w := doAuth(router, "GET", "/health", "guess-"+strconv.Itoa(i), ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i, w.Code)
+		}
+	}
+	w := doAuth(router, "GET", "/health", "guess-3", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d", w.Code)
+	}
+	if w := doAuth(router, "GET", "/health", "bootstrap", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("the IP quota applies to valid keys too, got %d", w.Code)
+	}
+}
+
+// Verifica que se rechazan los cuerpos demasiado grandes, declarados o no.
+func TestRouter_MaxBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithMaxBodyBytes(128))
+
+	body := `{"name":"api","endpoint":"http://127.0.0.1:1/health","frequency":3600,"emails":["` + strings.Repeat("a", 200) + `@x.com"]}`
+	if w := doAuth(router, "POST", "/register", "", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+
+	// Sin Content-Length la lectura se corta al superar el máximo
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge || storage.Get("api") != nil {
+		t.Fatalf("chunked oversized body: expected 413, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("PUT", "/services", strings.NewReader("["+body+"]"))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunked oversized sync body: expected 413, got %d", w.Code)
+	}
+}
+
+// Verifica que el máximo de servicios por tenant impide registrar nuevos
+// pero permite actualizar los existentes, y que cada tenant cuenta aparte.
+func TestRouter_MaxServicesPerTenant(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithMaxServicesPerTenant(1))
+
+	register := func(path, name string) int {
+		body := `{"name":"` + name + `","endpoint":"http://127.0.0.1:1/health","frequency":3600}`
+		return doAuth(router, "POST", path, "", body).Code
+	}
+	if code := register("/register", "api"); code != http.StatusCreated {
+		t.Fatalf("first service: expected 201, got %d", code)
+	}
+	if code := register("/register", "web"); code != http.StatusConflict {
+		t.Fatalf("over the limit: expected 409, got %d", code)
+	}
+	if code := register("/register", "api"); code != http.StatusCreated {
+		t.Fatalf("updating an existing service: expected 201, got %d", code)
+	}
+	if code := register("/t/team-a/register", "web"); code != http.StatusCreated {
+		t.Fatalf("other tenant: expected 201, got %d", code)
+	}
+}
+
+// Verifica que si el store no puede guardar el alta, /register responde 500
+// en lugar de dar por registrado el servicio.
+func TestRouter_RegisterStoreError(t *testing.T) {
+	t.Parallel()
+
+	storage, err := store.Open(store.BackendSQLite, filepath.Join(t.TempDir(), "services.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := api.SetupRouter(storage, api.WithMaxServicesPerTenant(5))
+	if err := storage.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"name":"api","endpoint":"http://127.0.0.1:1/health","frequency":3600}`
+	if code := doAuth(router, "POST", "/register", "", body).Code; code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+	if ok, err := storage.RegisterServiceLimited(models.Microservice{Name: "api", Endpoint: "http://a"}, 5); ok || err == nil {
+		t.Fatalf("expected an error from the closed store, got %v, %v", ok, err)
+	}
+}
+
+// Verifica que con altas simultáneas no se supera el máximo de servicios,
+// en los dos backends y contando cada tenant aparte.
+func TestStore_RegisterServiceLimitedConcurrent(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []string{store.BackendJSON, store.BackendSQLite} {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := store.Open(backend, filepath.Join(t.TempDir(), "services."+backend))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer root.Close()
+			root.RegisterService(models.Microservice{Name: "team-b/other", Tenant: "team-b", Endpoint: "http://b"})
+
+			for _, tenant := range []string{"", "team-a"} {
+				view := store.ForTenant(root, tenant)
+				var accepted atomic.Int32
+				var wg sync.WaitGroup
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						ok, err := view.RegisterServiceLimited(models.Microservice{Name: "svc-" + strconv.Itoa(i), Endpoint: "http://a"}, 3)
+						if err != nil {
+							t.Error(err)
+						}
+						if ok {
+							accepted.Add(1)
+						}
+					}(i)
+				}
+				wg.Wait()
+				all := view.GetAll()
+				if accepted.Load() != 3 || len(all) != 3 {
+					t.Fatalf("tenant %q: expected 3 services, accepted %d, stored %d", tenant, accepted.Load(), len(all))
+				}
+				for name := range all {
+					if ok, err := view.RegisterServiceLimited(models.Microservice{Name: name, Endpoint: "http://updated"}, 3); !ok || err != nil {
+						t.Fatalf("tenant %q: updating %s must be allowed (%v)", tenant, name, err)
+					}
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/incidents"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica la vigencia de ventanas únicas y recurrentes.
+func TestMaintenanceWindow_ActiveAt(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	once := models.MaintenanceWindow{Start: start, End: start.Add(time.Hour)}
+	daily := models.MaintenanceWindow{Start: start, End: start.Add(time.Hour), Recurrence: models.RecurrenceDaily}
+
+	if !once.ActiveAt(start.Add(30*time.Minute)) || once.ActiveAt(start.Add(2*time.Hour)) {
+		t.Fatal("unexpected one-off window activity")
+	}
+	if !daily.ActiveAt(start.Add(72*time.Hour + 10*time.Minute)) {
+		t.Fatal("expected daily window active three days later")
+	}
+	if daily.ActiveAt(start.Add(72*time.Hour+90*time.Minute)) || daily.ActiveAt(start.Add(-time.Hour)) {
+		t.Fatal("daily window active outside its slot")
+	}
+
+	tagged := models.MaintenanceWindow{Tags: []string{"team:perfil"}}
+	if !tagged.Covers(models.Microservice{Name: "x", Tags: []string{"team:perfil"}}) || tagged.Covers(models.Microservice{Name: "y"}) {
+		t.Fatal("unexpected tag matching")
+	}
+}
+
+// Una caída dentro de una ventana queda como MAINTENANCE, sin incidente y
+// fuera del cálculo de uptime.
+func TestMaintenance_SuppressesAlertsAndUptime(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "api-gateway", Endpoint: ts.URL, Frequency: 30, Status: "UP"})
+	router := api.SetupRouter(storage)
+
+	body := []byte(`{"services":["api-gateway"],"durationMinutes":5,"reason":"deploy"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/maintenance", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var window models.MaintenanceWindow
+	_ = json.Unmarshal(w.Body.Bytes(), &window)
+
+	result, _ := checker.CheckNow(storage, "api-gateway")
+	if result.Status != models.StatusMaintenance || !result.Maintenance {
+		t.Fatalf("expected MAINTENANCE result, got %+v", result)
+	}
+	if storage.ActiveIncident("api-gateway") != nil {
+		t.Fatal("no incident should be opened during maintenance")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/uptime/api-gateway?window=1h", nil))
+	var uptime report.Uptime
+	if err := json.Unmarshal(w.Body.Bytes(), &uptime); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if uptime.Maintenance != 1 || uptime.Down != 0 || uptime.UptimePercent != nil {
+		t.Fatalf("maintenance check must be excluded from uptime: %+v", uptime)
+	}
+
+	// Al terminar la ventana la caída sí abre un incidente
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/maintenance/"+window.ID, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	checker.CheckNow(storage, "api-gateway")
+	if storage.ActiveIncident("api-gateway") == nil {
+		t.Fatal("expected incident after maintenance ended")
+	}
+}
+
+// Una caída alertada que entra en mantenimiento y se recupera al terminar la
+// ventana cierra su incidente y avisa la recuperación.
+func TestMaintenance_RecoveryAfterWindowResolvesIncident(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy.Load() {
+			w.WriteHeader(200)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "billing", Endpoint: ts.URL, Frequency: 30, Emails: []string{"oncall@x.com"}, Status: "UP"})
+	router := api.SetupRouter(storage)
+
+	checker.CheckNow(storage, "billing")
+	incident := storage.ActiveIncident("billing")
+	if incident == nil || len(incident.Notifications) != 1 {
+		t.Fatalf("expected an alerted incident, got %+v", incident)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/maintenance", bytes.NewReader([]byte(`{"services":["billing"],"durationMinutes":5}`))))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var window models.MaintenanceWindow
+	_ = json.Unmarshal(w.Body.Bytes(), &window)
+	if result, _ := checker.CheckNow(storage, "billing"); result.Status != models.StatusMaintenance {
+		t.Fatalf("expected MAINTENANCE, got %+v", result)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/maintenance/"+window.ID, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	healthy.Store(true)
+	if result, _ := checker.CheckNow(storage, "billing"); result.Status != "UP" || result.PreviousStatus != models.StatusMaintenance {
+		t.Fatalf("expected MAINTENANCE -> UP, got %+v", result)
+	}
+	if storage.ActiveIncident("billing") != nil {
+		t.Fatal("the incident must be resolved after recovering")
+	}
+	resolved := storage.GetIncident(incident.ID)
+	if resolved.Status != models.IncidentResolved || len(resolved.Notifications) != 2 || resolved.Notifications[1].Kind != incidents.NotificationRecovery {
+		t.Fatalf("expected a resolved incident with a recovery notification, got %+v", resolved)
+	}
+}
+
+// Verifica que las ventanas del archivo de configuración se cargan al registrar.
+func TestRegistry_LoadsMaintenanceFromConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := `{
+	  "services": [{"name": "cfg-svc", "endpoint": "http://127.0.0.1:1/health", "frequency": 30, "tags": ["team:core"]}],
+	  "maintenance": [{"id": "nightly", "tags": ["team:core"], "start": "2024-01-01T02:00:00Z", "end": "2024-01-01T03:00:00Z", "recurrence": "daily"}]
+	}`
+	path := filepath.Join(dir, "services-config.json")
+	if err := os.WriteFile(path, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	windows := storage.ListMaintenance()
+	if len(windows) != 1 || windows[0].ID != "nightly" || windows[0].Source != "config" {
+		t.Fatalf("unexpected windows: %+v", windows)
+	}
+	if svc := storage.Get("cfg-svc"); svc == nil || !svc.HasTag("team:core") {
+		t.Fatalf("expected tagged service, got %+v", svc)
+	}
+}
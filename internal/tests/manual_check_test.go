@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que POST /services/:name/check ejecuta el check en el momento y devuelve el resultado.
+func TestAPI_CheckOne_RunsSynchronously(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+	storage.RegisterService(models.Microservice{Name: "manual", Endpoint: ts.URL, Frequency: 30, Status: "UP"})
+	router := api.SetupRouter(storage)
+
+	req := httptest.NewRequest("POST", "/services/manual/check", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body: %s", w.Code, w.Body.String())
+	}
+
+	var result models.CheckResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if result.Status != "DOWN" || result.PreviousStatus != "UP" || result.HTTPStatus != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Error == "" {
+		t.Fatal("expected error details in result")
+	}
+	if got := storage.Get("manual"); got.Status != "DOWN" {
+		t.Fatalf("expected store to be updated, got %q", got.Status)
+	}
+}
+
+// Verifica que POST /services/:name/check devuelve 404 para servicios desconocidos.
+func TestAPI_CheckOne_NotFound(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	router := api.SetupRouter(store.NewStoreWithPath(filepath.Join(tmp, "services.json")))
+
+	req := httptest.NewRequest("POST", "/services/missing/check", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// Verifica que POST /check verifica todos los servicios y devuelve un resultado por cada uno.
+func TestAPI_CheckAll(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	}))
+	defer ts.Close()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+	storage.RegisterService(models.Microservice{Name: "a", Endpoint: ts.URL, Frequency: 30})
+	storage.RegisterService(models.Microservice{Name: "b", Endpoint: ts.URL, Frequency: 30})
+	router := api.SetupRouter(storage)
+
+	req := httptest.NewRequest("POST", "/check", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var results []models.CheckResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "a" || results[1].Status != "UP" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+type readyzBody struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		OK     bool   `json:"ok"`
+		Detail string `json:"detail"`
+	} `json:"checks"`
+}
+
+func getReadyz(t *testing.T, storage store.Storage, scheduler *checker.Scheduler) (int, readyzBody) {
+	t.Helper()
+	r := gin.New()
+	r.GET("/readyz", api.ReadyzHandler(storage, scheduler))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	var body readyzBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	return w.Code, body
+}
+
+// Verifica que /livez responde sin depender del estado de los servicios monitoreados.
+func TestAPI_Livez(t *testing.T) {
+	t.Parallel()
+
+	router := api.SetupRouter(store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+// Verifica que /readyz falla mientras el scheduler no arrancó y pasa después.
+func TestAPI_ReadyzScheduler(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	scheduler := checker.NewScheduler(checker.SchedulerConfig{Workers: 1, Timeout: time.Second})
+
+	code, body := getReadyz(t, storage, scheduler)
+	if code != http.StatusServiceUnavailable || body.Checks["scheduler"].OK {
+		t.Fatalf("expected 503 with scheduler not running, got %d %+v", code, body)
+	}
+
+	scheduler.Start()
+	code, body = getReadyz(t, storage, scheduler)
+	if code != http.StatusOK || body.Status != "ok" {
+		t.Fatalf("expected 200 once started, got %d %+v", code, body)
+	}
+}
+
+// Verifica que /readyz detecta un store que ya no puede escribir, con ambos backends.
+func TestAPI_ReadyzStoreNotWritable(t *testing.T) {
+	t.Parallel()
+
+	scheduler := checker.NewScheduler(checker.SchedulerConfig{Workers: 1, Timeout: time.Second})
+	scheduler.Start()
+
+	dir := filepath.Join(t.TempDir(), "data")
+	storage, err := store.OpenStore(filepath.Join(dir, "services.json"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := storage.Ping(); err != nil {
+		t.Fatalf("expected writable store, got %v", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	code, body := getReadyz(t, storage, scheduler)
+	if code != http.StatusServiceUnavailable || body.Checks["store"].OK || body.Checks["store"].Detail == "" {
+		t.Fatalf("expected store check to fail, got %d %+v", code, body)
+	}
+
+	sqlite, err := store.OpenSQLite(filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer sqlite.Close()
+	if code, body := getReadyz(t, sqlite, scheduler); code != http.StatusOK {
+		t.Fatalf("expected sqlite store ready, got %d %+v", code, body)
+	}
+}
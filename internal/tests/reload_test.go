@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+)
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Verifica que recargar la configuración crea, actualiza y elimina los
+// servicios del archivo sin tocar los registrados vía API ni los que no
+// cambiaron.
+func TestConfigReload_ReconcilesServices(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "services-config.json")
+	writeConfig(t, path, `[
+	  {"name": "a", "endpoint": "http://127.0.0.1:1/a", "frequency": 3600},
+	  {"name": "b", "endpoint": "http://127.0.0.1:1/b", "frequency": 3600},
+	  {"name": "c", "endpoint": "http://127.0.0.1:1/c", "frequency": 3600, "tenant": "team-a"}
+	]`)
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	watcher := registry.NewConfigWatcher(storage, path, 0)
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+	storage.RegisterService(models.Microservice{Name: "manual", Endpoint: "http://127.0.0.1:1/m", Frequency: 3600, Source: registry.SourceAPI})
+	storage.UpdateService("a", "UP", time.Now().Format(time.RFC3339))
+
+	writeConfig(t, path, `[
+	  {"name": "a", "endpoint": "http://127.0.0.1:1/a", "frequency": 3600},
+	  {"name": "b", "endpoint": "http://127.0.0.1:1/b2", "frequency": 3600},
+	  {"name": "d", "endpoint": "http://127.0.0.1:1/d", "frequency": 3600}
+	]`)
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if storage.Get("a").Status != "UP" {
+		t.Fatal("unchanged service must keep its status")
+	}
+	if storage.Get("b").Endpoint != "http://127.0.0.1:1/b2" || storage.Get("d") == nil {
+		t.Fatalf("expected b updated and d created, got %+v", storage.GetAll())
+	}
+	if store.ForTenant(storage, "team-a").Get("c") != nil {
+		t.Fatal("service removed from the file must be deleted in its tenant")
+	}
+	if storage.Get("manual") == nil {
+		t.Fatal("services registered through the API must be kept")
+	}
+	for _, e := range storage.ListAudit() {
+		if e.Actor != models.AuditActorConfig {
+			t.Fatalf("unexpected audit actor: %+v", e)
+		}
+	}
+}
+
+// Verifica que una configuración inválida se rechaza entera y deja todo
+// como estaba, y que el watcher detecta los cambios del archivo.
+func TestConfigReload_RejectsInvalidAndWatches(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "services-config.json")
+	writeConfig(t, path, `[{"name": "a", "endpoint": "http://127.0.0.1:1/a", "frequency": 3600}]`)
+	storage := store.NewStoreWithPath(filepath.Join(dir, "services.json"))
+	watcher := registry.NewConfigWatcher(storage, path, 10*time.Millisecond)
+	if err := registry.AutoRegisterServices(storage, path); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]string{
+		"json":     `[{"name": "a",`,
+		"endpoint": `[{"name": "a", "endpoint": "http://127.0.0.1:1/a2"}, {"name": "b", "endpoint": "ftp://b"}]`,
+		"cycle": `[{"name": "a", "endpoint": "http://127.0.0.1:1/a", "dependsOn": ["b"]},
+		           {"name": "b", "endpoint": "http://127.0.0.1:1/b", "dependsOn": ["a"]}]`,
+		"maintenance": `{"services": [], "maintenance": [{"id": "x", "recurrence": "yearly"}]}`,
+	}
+	for name, data := range invalid {
+		writeConfig(t, path, data)
+		if err := watcher.Reload(); err == nil {
+			t.Fatalf("%s: expected invalid config to be rejected", name)
+		}
+		if a := storage.Get("a"); a == nil || a.Endpoint != "http://127.0.0.1:1/a" || storage.Get("b") != nil {
+			t.Fatalf("%s: rejected config must not change services, got %+v", name, storage.GetAll())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+	writeConfig(t, path, `[{"name": "a", "endpoint": "http://127.0.0.1:1/a"}, {"name": "b", "endpoint": "http://127.0.0.1:1/b"}]`)
+	deadline := time.Now().Add(2 * time.Second)
+	for storage.Get("b") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not pick up the file change")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
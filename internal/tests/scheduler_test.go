@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que un pool pequeño de workers termina verificando muchos servicios.
+func TestScheduler_ManyServicesFewWorkers(t *testing.T) {
+	t.Parallel()
+
+	var hits int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	}))
+	defer ts.Close()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+	scheduler := checker.NewScheduler(checker.SchedulerConfig{Workers: 2, Timeout: time.Second})
+
+	const total = 20
+	for i := 0; i < total; i++ {
+		storage.RegisterService(models.Microservice{
+			Name:      fmt.Sprintf("svc-%02d", i),
+			Endpoint:  ts.URL,
+			Frequency: 30,
+			Status:    "UNKNOWN",
+		})
+	}
+	for _, svc := range storage.GetAll() {
+		scheduler.Schedule(storage, svc)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		up := 0
+		for _, svc := range storage.GetAll() {
+			if svc.Status == "UP" {
+				up++
+			}
+		}
+		if up == total {
+			stats := scheduler.Stats()
+			if stats.Workers != 2 || stats.Scheduled != total {
+				t.Fatalf("unexpected stats: %+v", stats)
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("not all services checked; hits=%d stats=%+v", atomic.LoadInt64(&hits), scheduler.Stats())
+}
+
+// Verifica que reprogramar el mismo servicio no duplica entradas en la cola.
+func TestScheduler_RescheduleIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	storage := store.NewStoreWithPath(filepath.Join(tmp, "services.json"))
+	scheduler := checker.NewScheduler(checker.SchedulerConfig{Workers: 1, Timeout: 100 * time.Millisecond})
+
+	svc := models.Microservice{Name: "dup", Endpoint: "http://127.0.0.1:1", Frequency: 30}
+	storage.RegisterService(svc)
+	scheduler.Schedule(storage, &svc)
+	scheduler.Schedule(storage, &svc)
+
+	if !scheduler.IsScheduled(storage, "dup") {
+		t.Fatal("expected service to be scheduled")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if got := scheduler.Stats().Scheduled + scheduler.Stats().InFlight; got != 1 {
+		t.Fatalf("expected a single queue entry, got %d", got)
+	}
+}
+
+// Verifica que GET /scheduler expone las métricas de la cola.
+func TestAPI_SchedulerStats(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	router := api.SetupRouter(store.NewStoreWithPath(filepath.Join(tmp, "services.json")))
+
+	req := httptest.NewRequest("GET", "/scheduler", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var stats checker.SchedulerStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if stats.Workers <= 0 {
+		t.Fatalf("expected workers > 0, got %+v", stats)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que el backend SQLite persiste servicios e historial entre aperturas.
+func TestSQLiteStore_PersistsAcrossReopen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "health.db")
+	s, err := store.Open(store.BackendSQLite, path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	s.RegisterService(models.Microservice{
+		Name:      "db-svc",
+		Endpoint:  "http://example.com",
+		Frequency: 30,
+		Emails:    []string{"a@b.com"},
+		Status:    "UNKNOWN",
+	})
+	now := time.Now()
+	s.RecordResult("db-svc", models.CheckResult{Name: "db-svc", Status: "DOWN", Timestamp: now.Add(-time.Minute), ErrorClass: models.ErrorClassTimeout})
+	s.RecordResult("db-svc", models.CheckResult{Name: "db-svc", Status: "UP", Timestamp: now, HTTPStatus: 200})
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Reabrir: las migraciones ya aplicadas no deben volver a ejecutarse
+	s, err = store.Open(store.BackendSQLite, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	got := s.Get("db-svc")
+	if got == nil || got.Status != "UP" || got.LastResult == nil || got.LastResult.HTTPStatus != 200 {
+		t.Fatalf("unexpected service after reopen: %+v", got)
+	}
+	if len(got.Emails) != 1 || got.Emails[0] != "a@b.com" {
+		t.Fatalf("emails not persisted: %+v", got.Emails)
+	}
+
+	history := s.History("db-svc", now.Add(-time.Hour))
+	if len(history) != 2 || history[0].Status != "DOWN" || history[1].Status != "UP" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if recent := s.History("db-svc", now.Add(-time.Second)); len(recent) != 1 {
+		t.Fatalf("expected history filtered by time, got %d", len(recent))
+	}
+	s.RecordResult("db-svc", models.CheckResult{Status: "DOWN", Timestamp: now, Maintenance: true})
+	stats := s.DailyStats("db-svc", now.Add(-time.Hour))
+	var total models.DailyStats
+	for _, d := range stats {
+		total.Checks += d.Checks
+		total.Up += d.Up
+		total.Down += d.Down
+		total.Maintenance += d.Maintenance
+	}
+	if total.Checks != 3 || total.Up != 1 || total.Down != 1 || total.Maintenance != 1 {
+		t.Fatalf("unexpected daily stats: %+v", stats)
+	}
+	if len(s.GetAll()) != 1 {
+		t.Fatalf("expected one service")
+	}
+}
+
+// Verifica la selección de backend y el error con un backend desconocido.
+func TestStore_OpenBackends(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	s, err := store.Open("json", filepath.Join(tmp, "services.json"))
+	if err != nil {
+		t.Fatalf("json backend: %v", err)
+	}
+	s.RegisterService(models.Microservice{Name: "j", Endpoint: "http://x"})
+	s.RecordResult("j", models.CheckResult{Status: "UP", Timestamp: time.Now()})
+	if len(s.History("j", time.Time{})) != 1 {
+		t.Fatal("expected json backend to keep in-memory history")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if _, err := store.Open("mongo", ""); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica el estado agregado de la plataforma según la criticidad de los
+// servicios caídos.
+func TestAPI_PlatformStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		gateway    string // estado del servicio crítico
+		reports    string // estado del no crítico
+		wantStatus string
+		wantCode   int
+	}{
+		{"all up", "UP", "UP", report.StatusUp, http.StatusOK},
+		{"non critical down", "UP", "DOWN", report.StatusDegraded, http.StatusOK},
+		{"critical down", "DOWN", "UP", report.StatusDown, http.StatusServiceUnavailable},
+		{"critical in maintenance", models.StatusMaintenance, "UNKNOWN", report.StatusUp, http.StatusOK},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+			storage.RegisterService(models.Microservice{Name: "api-gateway", Endpoint: "http://a", Status: tc.gateway, Critical: true})
+			storage.RegisterService(models.Microservice{Name: "reports", Endpoint: "http://b", Status: tc.reports})
+			router := api.SetupRouter(storage)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, w.Code)
+			}
+			var platform report.Platform
+			if err := json.Unmarshal(w.Body.Bytes(), &platform); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if platform.Status != tc.wantStatus || platform.Services != 2 || platform.Critical != 1 {
+				t.Fatalf("unexpected platform status: %+v", platform)
+			}
+		})
+	}
+}
+
+// Verifica que ?tag= limita el estado a un subconjunto, p. ej. producción.
+func TestAPI_PlatformStatusByTag(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "gw-prod", Endpoint: "http://a", Status: "UP", Critical: true, Tags: []string{"env:prod"}})
+	storage.RegisterService(models.Microservice{Name: "gw-staging", Endpoint: "http://b", Status: "DOWN", Critical: true, Tags: []string{"env:staging"}})
+	router := api.SetupRouter(storage)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/status?tag=env:prod", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected prod to be healthy, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("HEAD", "/status", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for whole platform, got %d", w.Code)
+	}
+}
+
+// Verifica que sin lecturas públicas /status sigue respondiendo a los
+// balanceadores pero sin nombres de servicios, salvo con scope read.
+func TestAPI_PlatformStatusWithoutPublicRead(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "secret-gateway", Endpoint: "http://a", Status: "DOWN", Critical: true})
+	storage.RegisterService(models.Microservice{Name: "secret-reports", Endpoint: "http://b", Status: "UP"})
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{
+		{Name: "reader", Hash: auth.Hash("reader-secret"), Scopes: []string{"read"}},
+		{Name: "writer", Hash: auth.Hash("writer-secret"), Scopes: []string{"register"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "bootstrap"})))
+
+	for _, key := range []string{"", "wrong", "writer-secret"} {
+		w := doAuth(router, "GET", "/status", key, "")
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("key %q: expected 503, got %d", key, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "secret-") {
+			t.Fatalf("key %q: service names must not be exposed: %s", key, w.Body.String())
+		}
+		var summary report.Summary
+		if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+			t.Fatal(err)
+		}
+		if summary.Status != report.StatusDown || summary.Services != 2 || summary.CriticalDown != 1 {
+			t.Fatalf("key %q: unexpected summary %+v", key, summary)
+		}
+	}
+
+	w := doAuth(router, "GET", "/status", "reader-secret", "")
+	var platform report.Platform
+	if err := json.Unmarshal(w.Body.Bytes(), &platform); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusServiceUnavailable || len(platform.CriticalDown) != 1 || platform.CriticalDown[0] != "secret-gateway" {
+		t.Fatalf("reader must see the details, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que el uptime diario ubica el resumen de cada día UTC.
+func TestReport_DailyUptime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	stats := []models.DailyStats{
+		{Date: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), Checks: 1, Down: 1}, // fuera de la ventana
+		{Date: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC), Checks: 1, Up: 1},
+		{Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Checks: 3, Up: 1, Down: 1, Maintenance: 1},
+	}
+	days := report.DailyUptime(stats, 3, now)
+	if len(days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(days))
+	}
+	if !days[0].Date.Equal(time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)) || days[0].Checks != 1 || *days[0].UptimePercent != 100 {
+		t.Fatalf("unexpected first day: %+v", days[0])
+	}
+	if days[1].UptimePercent != nil {
+		t.Fatalf("expected no data for the middle day, got %+v", days[1])
+	}
+	if days[2].Checks != 3 || days[2].Down != 1 || *days[2].UptimePercent != 50 {
+		t.Fatalf("unexpected last day: %+v", days[2])
+	}
+	if n := report.CoveredDays(days); n != 3 {
+		t.Fatalf("expected 3 covered days, got %d", n)
+	}
+}
+
+// Verifica que el backend JSON conserva el resumen diario entre reinicios,
+// aunque el historial detallado quede en memoria y recortado.
+func TestStore_DailyStatsPersisted(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	s := store.NewStoreWithPath(path)
+	s.RegisterService(models.Microservice{Name: "api", Endpoint: "http://a"})
+	now := time.Now()
+	s.RecordResult("api", models.CheckResult{Status: "DOWN", Timestamp: now.AddDate(0, 0, -3)})
+	for i := 0; i < 1200; i++ {
+		s.RecordResult("api", models.CheckResult{Status: "UP", Timestamp: now.Add(-time.Duration(1200-i) * time.Second)})
+	}
+	s.RecordResult("api", models.CheckResult{Status: "DOWN", Timestamp: now.AddDate(0, 0, -200)}) // más viejo que HistoryDays
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := store.NewStoreWithPath(path)
+	if h := reopened.History("api", time.Time{}); len(h) != 0 {
+		t.Fatalf("detailed history must not be persisted, got %d", len(h))
+	}
+	stats := reopened.DailyStats("api", now.AddDate(0, 0, -store.HistoryDays))
+	u := report.ComputeDailyUptime("api", "", stats)
+	if u.Checks != 1201 || u.Down != 1 {
+		t.Fatalf("expected every check within HistoryDays in the rollup, got %+v (%+v)", u, stats)
+	}
+	if n := report.CoveredDays(report.DailyUptime(stats, store.HistoryDays, now)); n != 4 {
+		t.Fatalf("expected 4 covered days, got %d", n)
+	}
+}
+
+// Verifica que el historial en memoria del backend JSON cubre la ventana de
+// 24h a la frecuencia del servicio.
+func TestStore_HistoryCoversDay(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	s.RegisterService(models.Microservice{Name: "api", Endpoint: "http://a", Frequency: 30})
+	now := time.Now()
+	for i := 25 * 60 * 2; i >= 0; i-- {
+		s.RecordResult("api", models.CheckResult{Status: "UP", Timestamp: now.Add(-time.Duration(i) * 30 * time.Second)})
+	}
+	u := report.WindowUptime(s, "api", "24h", 24*time.Hour, now)
+	if _, full := u.Coverage(now, 30*time.Second); !full || u.Checks < 24*60*2 {
+		t.Fatalf("expected the whole day in the detailed history, got %d checks from %v", u.Checks, u.From)
+	}
+}
+
+// Verifica que /status-page devuelve HTML autocontenido con los grupos,
+// incidentes y mantenimientos.
+func TestAPI_StatusPage(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "perfil-api", Endpoint: "http://a", Status: "DOWN", Tags: []string{"team:perfil"}})
+	storage.RegisterService(models.Microservice{Name: "suelto", Endpoint: "http://b", Status: "UP"})
+	storage.RecordResult("perfil-api", models.CheckResult{Name: "perfil-api", Status: "DOWN", Timestamp: time.Now()})
+	storage.CreateIncident(models.Incident{ID: "inc_1", Service: "perfil-api", Status: models.IncidentOpen, StartedAt: time.Now()})
+	storage.SaveMaintenance(models.MaintenanceWindow{ID: "mnt_1", Services: []string{"suelto"}, Reason: "<migración>",
+		Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Hour)})
+	router := api.SetupRouter(storage)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/status-page", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<h2>perfil</h2>", "<h2>Otros servicios</h2>", "perfil-api", "Incidentes activos",
+		"&lt;migración&gt;", "en curso", "Degradación parcial", "0.00% en 1 día", "sin datos"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected page to contain %q", want)
+		}
+	}
+	if strings.Count(body, `title="`) != 2*90 {
+		t.Fatalf("expected 90 uptime bars per service")
+	}
+	if strings.Contains(body, "<script") || strings.Contains(body, "https://") {
+		t.Fatal("page must not depend on external scripts or resources")
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que una ráfaga de actualizaciones se agrupa en una escritura diferida.
+func TestStore_DebouncedPersistence(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	s := store.NewStoreWithPath(path)
+	s.RegisterService(models.Microservice{Name: "burst", Endpoint: "http://x", Status: "UNKNOWN"})
+	for i := 0; i < 100; i++ {
+		s.UpdateService("burst", "UP", "t")
+	}
+	s.UpdateService("burst", "DOWN", "last")
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected write to be deferred, stat err: %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var doc struct {
+		Services []models.Microservice `json:"services"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid file: %v", err)
+	}
+	if len(doc.Services) != 1 || doc.Services[0].Status != "DOWN" || doc.Services[0].LastCheck != "last" {
+		t.Fatalf("unexpected persisted state: %+v", doc.Services)
+	}
+}
+
+// Verifica que se conserva un respaldo y que se usa si el archivo principal está corrupto.
+func TestStore_RecoversFromBackup(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	s, err := store.OpenStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	s.RegisterService(models.Microservice{Name: "first", Endpoint: "http://x"})
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s.RegisterService(models.Microservice{Name: "second", Endpoint: "http://y"})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Fatalf("expected backup file: %v", err)
+	}
+
+	// Simular un archivo truncado por un crash
+	if err := os.WriteFile(path, []byte(`[{"name":"sec`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := store.OpenStore(path)
+	if err != nil {
+		t.Fatalf("expected recovery from backup, got %v", err)
+	}
+	if recovered.Get("first") == nil {
+		t.Fatal("expected service from backup")
+	}
+}
+
+// Verifica que se sigue leyendo el formato anterior (solo la lista de servicios).
+func TestStore_LoadsLegacyFormat(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	legacy := `[{"name":"old","endpoint":"http://x","frequency":30,"emails":[],"status":"UP","lastCheck":""}]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.OpenStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got := s.Get("old"); got == nil || got.Status != "UP" {
+		t.Fatalf("legacy service not loaded: %+v", got)
+	}
+}
+
+// Verifica que un archivo corrupto sin respaldo válido se reporta como error.
+func TestStore_LoadErrorIsSurfaced(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "services.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.OpenStore(path); err == nil {
+		t.Fatal("expected load error")
+	}
+	if _, err := store.Open(store.BackendJSON, path); err == nil {
+		t.Fatal("expected load error from Open")
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que las lecturas del store son copias que no alteran el estado interno.
+func TestStore_ReturnsSnapshots(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	s.RegisterService(models.Microservice{Name: "snap", Endpoint: "http://x", Emails: []string{"a@b"}, Status: "UP"})
+
+	got := s.Get("snap")
+	got.Status = "DOWN"
+	got.Emails[0] = "changed"
+	for _, svc := range s.GetAll() {
+		svc.Status = "DOWN"
+	}
+
+	fresh := s.Get("snap")
+	if fresh.Status != "UP" || fresh.Emails[0] != "a@b" {
+		t.Fatalf("store state was mutated through a snapshot: %+v", fresh)
+	}
+}
+
+// Ejecuta lecturas vía API y escrituras del checker en paralelo; con -race
+// detecta accesos concurrentes al estado interno del store.
+func TestStore_ConcurrentReadsAndUpdates(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	s.RegisterService(models.Microservice{Name: "busy", Endpoint: "http://x", Status: "UNKNOWN"})
+	router := api.SetupRouter(s)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			status := "UP"
+			if i%2 == 0 {
+				status = "DOWN"
+			}
+			s.RecordResult("busy", models.CheckResult{Name: "busy", Status: status, Timestamp: time.Now()})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+			var services map[string]*models.Microservice
+			if err := json.Unmarshal(w.Body.Bytes(), &services); err != nil {
+				t.Errorf("invalid body: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	previous, ok := s.RecordResult("busy", models.CheckResult{Status: "UP", Timestamp: time.Now()})
+	if !ok || previous != "UP" {
+		t.Fatalf("expected previous status UP from last write, got %q (ok=%v)", previous, ok)
+	}
+}
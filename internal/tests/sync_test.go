@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/registry"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que PUT /services crea, actualiza y con prune elimina servicios,
+// que dryRun solo devuelve el diff y que los servicios sin cambios
+// conservan su estado.
+func TestServicesSync_Reconciles(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "keep", Endpoint: "http://127.0.0.1:1/keep", Frequency: 3600, Status: "UP", Source: registry.SourceAPI})
+	storage.RegisterService(models.Microservice{Name: "change", Endpoint: "http://127.0.0.1:1/old", Frequency: 3600, Status: "UP", Source: registry.SourceAPI})
+	storage.RegisterService(models.Microservice{Name: "old", Endpoint: "http://127.0.0.1:1/gone", Frequency: 3600, Status: "UP", Source: registry.SourceAPI})
+	router := api.SetupRouter(storage)
+
+	body := `{"services": [
+	  {"name": "keep", "endpoint": "http://127.0.0.1:1/keep", "frequency": 3600, "emails": []},
+	  {"name": "change", "endpoint": "http://127.0.0.1:1/new", "frequency": 3600},
+	  {"name": "new", "endpoint": "http://127.0.0.1:1/new", "frequency": 3600}
+	]}`
+
+	w := doAuth(router, "PUT", "/services?prune=true&dryRun=true", "", body)
+	var plan registry.SyncResult
+	if err := json.Unmarshal(w.Body.Bytes(), &plan); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("dry run: got %d %s", w.Code, w.Body.String())
+	}
+	if !plan.DryRun || plan.Created != 1 || plan.Updated != 1 || plan.Deleted != 1 || plan.Unchanged != 1 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if c := plan.Changes[0]; c.Service != "change" || c.Action != registry.SyncUpdate || len(c.Changes) != 1 || c.Changes[0].Field != "endpoint" {
+		t.Fatalf("unexpected update diff: %+v", plan.Changes)
+	}
+	if storage.Get("new") != nil || storage.Get("old") == nil {
+		t.Fatal("dry run must not change the store")
+	}
+
+	w = doAuth(router, "PUT", "/services?prune=true", "", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("sync: got %d %s", w.Code, w.Body.String())
+	}
+	if storage.Get("new") == nil || storage.Get("old") != nil || storage.Get("change").Endpoint != "http://127.0.0.1:1/new" {
+		t.Fatalf("store not reconciled: %+v", storage.GetAll())
+	}
+	if storage.Get("keep").Status != "UP" {
+		t.Fatal("unchanged service must keep its status")
+	}
+	if audit := storage.ListAudit(); len(audit) != 3 {
+		t.Fatalf("expected 3 audit entries, got %+v", audit)
+	}
+
+	// Repetir la misma lista no cambia nada
+	w = doAuth(router, "PUT", "/services?prune=true", "", body)
+	var again registry.SyncResult
+	_ = json.Unmarshal(w.Body.Bytes(), &again)
+	if again.Unchanged != 3 || len(again.Changes) != 0 {
+		t.Fatalf("expected no changes, got %s", w.Body.String())
+	}
+}
+
+// Verifica que una lista con problemas se rechaza entera, informando todos.
+func TestServicesSync_RejectsInvalidList(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage)
+
+	body := `[
+	  {"name": "ok", "endpoint": "http://127.0.0.1:1/ok", "frequency": 3600},
+	  {"name": "bad", "endpoint": "ftp://x", "frequency": 3600},
+	  {"name": "a/b", "endpoint": "http://127.0.0.1:1/x", "frequency": 3600}
+	]`
+	w := doAuth(router, "PUT", "/services", "", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Problems []string `json:"problems"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %s", w.Body.String())
+	}
+	if len(storage.GetAll()) != 0 {
+		t.Fatal("an invalid list must not change the store")
+	}
+
+	cycle := `[
+	  {"name": "a", "endpoint": "http://127.0.0.1:1/a", "dependsOn": ["b"]},
+	  {"name": "b", "endpoint": "http://127.0.0.1:1/b", "dependsOn": ["a"]}
+	]`
+	if w := doAuth(router, "PUT", "/services", "", cycle); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a dependency cycle, got %d", w.Code)
+	}
+	if w := doAuth(router, "PUT", "/t/team-a/services", "", `[{"name": "x", "tenant": "team-b", "endpoint": "http://127.0.0.1:1/x"}]`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a foreign tenant, got %d", w.Code)
+	}
+}
+
+// Verifica que actualizar un servicio caído conserva su estado: no se
+// repite la alerta mientras siga caído y al recuperarse se cierra el
+// incidente.
+func TestServicesSync_UpdateKeepsDownState(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy.Load() {
+			w.WriteHeader(200)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "payments", Endpoint: ts.URL, Frequency: 3600, Emails: []string{"a@x.com"}, Status: "UP"})
+	checker.CheckNow(storage, "payments")
+	incident := storage.ActiveIncident("payments")
+	if incident == nil || len(incident.Notifications) != 1 {
+		t.Fatalf("expected an alerted incident, got %+v", incident)
+	}
+
+	_, err := registry.Reconcile(storage, []registry.ServiceConfig{
+		{Name: "payments", Endpoint: ts.URL, Frequency: 3600, Emails: []string{"a@x.com", "b@x.com"}},
+	}, registry.SyncOptions{Source: registry.SourceAPI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := storage.Get("payments")
+	if updated.Status != "DOWN" || updated.LastResult == nil || len(updated.Emails) != 2 {
+		t.Fatalf("update must keep the DOWN state, got %+v", updated)
+	}
+
+	checker.CheckNow(storage, "payments")
+	if got := storage.GetIncident(incident.ID); len(got.Notifications) != 1 {
+		t.Fatalf("still down must not alert again, got %+v", got.Notifications)
+	}
+
+	healthy.Store(true)
+	checker.CheckNow(storage, "payments")
+	if storage.ActiveIncident("payments") != nil {
+		t.Fatal("the incident must be resolved after recovering")
+	}
+}
+
+// racingStore ejecuta race justo después de la primera lectura de todos los
+// servicios, como una alta concurrente entre el snapshot y las escrituras.
+type racingStore struct {
+	store.Storage
+	once sync.Once
+	race func()
+}
+
+func (s *racingStore) GetAll() map[string]*models.Microservice {
+	all := s.Storage.GetAll()
+	s.once.Do(s.race)
+	return all
+}
+
+// Verifica que una alta por POST /register entre el snapshot y las
+// escrituras de la sincronización no hace superar el máximo de servicios.
+func TestServicesSync_MaxServicesWithConcurrentRegister(t *testing.T) {
+	t.Parallel()
+
+	root := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage := &racingStore{Storage: root, race: func() {
+		_, _ = root.RegisterServiceLimited(models.Microservice{Name: "api", Endpoint: "http://127.0.0.1:1/a", Frequency: 3600}, 2)
+	}}
+	list := []registry.ServiceConfig{
+		{Name: "sync-0", Endpoint: "http://127.0.0.1:1/s", Frequency: 3600},
+		{Name: "sync-1", Endpoint: "http://127.0.0.1:1/s", Frequency: 3600},
+	}
+
+	_, err := registry.Reconcile(storage, list, registry.SyncOptions{Source: registry.SourceAPI, MaxServices: 2})
+	if !errors.Is(err, registry.ErrServiceLimit) {
+		t.Fatalf("expected ErrServiceLimit, got %v", err)
+	}
+	if n := len(root.GetAll()); n != 2 {
+		t.Fatalf("expected 2 services, got %d", n)
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/notifier"
+	"health-check-app-micro/internal/report"
+	"health-check-app-micro/internal/store"
+)
+
+func newTaggedStore(t *testing.T) *store.Store {
+	t.Helper()
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	storage.RegisterService(models.Microservice{Name: "perfil-prod", Endpoint: "http://a", Status: "DOWN",
+		Tags: []string{"team:perfil", "env:prod"}})
+	storage.RegisterService(models.Microservice{Name: "perfil-staging", Endpoint: "http://b", Status: "DOWN",
+		Tags: []string{"team:perfil", "env:staging"}})
+	storage.RegisterService(models.Microservice{Name: "perfil-worker", Endpoint: "http://c", Status: "UP",
+		Tags: []string{"team:perfil", "env:prod"}})
+	storage.RegisterService(models.Microservice{Name: "jwt-prod", Endpoint: "http://d", Status: "UP",
+		Tags: []string{"team:auth", "env:prod"}})
+	return storage
+}
+
+// Verifica el filtrado de /health por tags (todos deben cumplirse) y estado.
+func TestAPI_HealthFilterByTagAndStatus(t *testing.T) {
+	t.Parallel()
+
+	router := api.SetupRouter(newTaggedStore(t))
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"?tag=team:perfil&status=DOWN", []string{"perfil-prod", "perfil-staging"}},
+		{"?tag=team:perfil&tag=env:prod", []string{"perfil-prod", "perfil-worker"}},
+		{"?status=up", []string{"jwt-prod", "perfil-worker"}},
+		{"?tag=team:nadie", nil},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/health"+tc.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tc.query, w.Code)
+		}
+		var got map[string]models.Microservice
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: invalid body: %v", tc.query, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: expected %v, got %d services", tc.query, tc.want, len(got))
+		}
+		for _, name := range tc.want {
+			if _, ok := got[name]; !ok {
+				t.Fatalf("%s: expected %s in result", tc.query, name)
+			}
+		}
+	}
+}
+
+// Verifica el estado agregado por grupo.
+func TestAPI_GroupsByTag(t *testing.T) {
+	t.Parallel()
+
+	router := api.SetupRouter(newTaggedStore(t))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/groups?by=team", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var groups []report.Group
+	if err := json.Unmarshal(w.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Value != "auth" || groups[1].Value != "perfil" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if groups[0].Status != report.StatusUp || groups[1].Status != report.StatusDegraded || groups[1].Down != 2 {
+		t.Fatalf("unexpected aggregate status: %+v", groups)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/groups?by=env&tag=team:perfil&status=DOWN", nil))
+	groups = nil
+	_ = json.Unmarshal(w.Body.Bytes(), &groups)
+	if len(groups) != 2 || groups[0].Status != report.StatusDown || groups[1].Status != report.StatusDown {
+		t.Fatalf("expected filtered groups all DOWN, got %+v", groups)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/groups", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without by, got %d", w.Code)
+	}
+}
+
+// Verifica que las rutas por tag suman destinatarios sin repetirlos.
+func TestNotifier_RecipientsByTagRoute(t *testing.T) {
+	notifier.SetRoutes([]notifier.Route{
+		{Tag: "team:perfil", Emails: []string{"perfil@x.com", "oncall@x.com"}},
+		{Tag: "env:staging", Emails: []string{"qa@x.com"}},
+	})
+	defer notifier.SetRoutes(nil)
+
+	svc := &models.Microservice{Name: "perfil", Emails: []string{"oncall@x.com"}, Tags: []string{"team:perfil", "env:prod"}}
+	got := notifier.Recipients(svc)
+	if len(got) != 2 || got[0] != "oncall@x.com" || got[1] != "perfil@x.com" {
+		t.Fatalf("unexpected recipients: %v", got)
+	}
+}
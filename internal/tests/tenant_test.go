@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"health-check-app-micro/internal/api"
+	"health-check-app-micro/internal/auth"
+	"health-check-app-micro/internal/checker"
+	"health-check-app-micro/internal/models"
+	"health-check-app-micro/internal/notifier"
+	"health-check-app-micro/internal/store"
+)
+
+// Verifica que dos tenants registran el mismo nombre sin pisarse y que cada
+// uno solo ve sus servicios e incidentes.
+func TestTenants_IsolatedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	// Mismo servidor para ambos: caído para team-a y sano para team-b
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/team-a" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer target.Close()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage)
+
+	for _, tenant := range []string{"team-a", "team-b"} {
+		body := `{"name":"api","endpoint":"` + target.URL + `/` + tenant + `","frequency":3600}`
+		if w := doAuth(router, "POST", "/t/"+tenant+"/register", "", body); w.Code != http.StatusCreated {
+			t.Fatalf("%s register: expected 201, got %d %s", tenant, w.Code, w.Body.String())
+		}
+	}
+
+	var services map[string]models.Microservice
+	w := doAuth(router, "GET", "/t/team-a/health", "", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &services)
+	if len(services) != 1 || services["api"].Endpoint != target.URL+"/team-a" || services["api"].Tenant != "team-a" {
+		t.Fatalf("unexpected team-a services: %s", w.Body.String())
+	}
+	if w := doAuth(router, "GET", "/health", "", ""); w.Body.String() != "{}" {
+		t.Fatalf("default tenant must not see other tenants, got %s", w.Body.String())
+	}
+	if w := doAuth(router, "GET", "/t/Bad_Tenant/health", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("invalid tenant: expected 404, got %d", w.Code)
+	}
+	if all := storage.GetAll(); all["team-a/api"] == nil || all["team-b/api"] == nil {
+		t.Fatalf("expected services stored under tenant keys, got %v", all)
+	}
+
+	if _, ok := checker.CheckNow(store.ForTenant(storage, "team-a"), "api"); !ok {
+		t.Fatal("expected team-a/api to be checked")
+	}
+	var list []models.Incident
+	w = doAuth(router, "GET", "/t/team-a/incidents", "", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Service != "api" {
+		t.Fatalf("expected one team-a incident, got %s", w.Body.String())
+	}
+	if w := doAuth(router, "GET", "/t/team-b/incidents", "", ""); w.Body.String() != "[]" {
+		t.Fatalf("team-b must not see team-a incidents, got %s", w.Body.String())
+	}
+	if w := doAuth(router, "POST", "/t/team-b/incidents/"+list[0].ID+"/ack", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("ack from another tenant: expected 404, got %d", w.Code)
+	}
+	if svc := store.ForTenant(storage, "team-b").Get("api"); svc.Status == "DOWN" {
+		t.Fatalf("team-b/api must not be affected by team-a checks, got %s", svc.Status)
+	}
+}
+
+// Verifica que una key creada en un tenant solo da acceso a ese tenant.
+func TestTenants_ScopedAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "root-secret"})))
+
+	w := doAuth(router, "POST", "/t/team-a/admin/apikeys", "root-secret", `{"name":"team-a-admin","scopes":["admin"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			Tenant string `json:"tenant"`
+		} `json:"apiKey"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.APIKey.Tenant != "team-a" {
+		t.Fatalf("expected key bound to team-a, got %s", w.Body.String())
+	}
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/t/team-a/health", http.StatusOK},
+		{"GET", "/t/team-a/admin/apikeys", http.StatusOK},
+		{"GET", "/t/team-b/health", http.StatusForbidden},
+		{"GET", "/health", http.StatusForbidden},
+		{"GET", "/admin/apikeys", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if w := doAuth(router, tc.method, tc.path, created.Key, ""); w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+
+	if w := doAuth(router, "GET", "/admin/apikeys", "root-secret", ""); w.Body.String() != "[]" {
+		t.Fatalf("global listing must not include tenant keys, got %s", w.Body.String())
+	}
+}
+
+// Verifica que una key del tenant por defecto no opera en otros tenants:
+// solo ADMIN_API_KEY y las keys declaradas con allTenants lo hacen.
+func TestTenants_DefaultTenantKeyIsScoped(t *testing.T) {
+	t.Parallel()
+
+	storage := store.NewStoreWithPath(filepath.Join(t.TempDir(), "services.json"))
+	router := api.SetupRouter(storage, api.WithAuth(auth.New(storage, auth.Config{BootstrapKey: "root-secret"})))
+
+	w := doAuth(router, "POST", "/admin/apikeys", "root-secret", `{"name":"ci","scopes":["read","register"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	body := `{"name":"api","endpoint":"http://127.0.0.1:1/health","frequency":3600}`
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"GET", "/health", "", http.StatusOK},
+		{"GET", "/t/team-a/health", "", http.StatusForbidden},
+		{"POST", "/t/team-a/register", body, http.StatusForbidden},
+		{"PUT", "/t/team-a/services", `{"services":[]}`, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if w := doAuth(router, tc.method, tc.path, created.Key, tc.body); w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d %s", tc.method, tc.path, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if len(store.ForTenant(storage, "team-a").GetAll()) != 0 {
+		t.Fatal("default-tenant key must not register in team-a")
+	}
+
+	hash := auth.Hash("global-secret")
+	if err := auth.SyncConfig(storage, []auth.ConfigKey{{Name: "ops", Hash: hash, Scopes: []string{"register"}, AllTenants: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if w := doAuth(router, "POST", "/t/team-a/register", "global-secret", body); w.Code != http.StatusCreated {
+		t.Fatalf("allTenants key: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	if err := auth.ValidateConfig([]auth.ConfigKey{{Name: "x", Hash: hash, Scopes: []string{"read"}, Tenant: "team-a", AllTenants: true}}); err == nil {
+		t.Fatal("allTenants with a tenant must be rejected")
+	}
+}
+
+// Verifica que las reglas de ruteo de alertas solo aplican a su tenant.
+func TestNotifier_RoutesByTenant(t *testing.T) {
+	notifier.SetRoutes([]notifier.Route{
+		{Tenant: "team-a", Tag: "env:prod", Emails: []string{"a@x.com"}},
+		{Tag: "env:prod", Emails: []string{"default@x.com"}},
+	})
+	defer notifier.SetRoutes(nil)
+
+	a := &models.Microservice{Name: "api", Tenant: "team-a", Tags: []string{"env:prod"}}
+	if got := notifier.Recipients(a); len(got) != 1 || got[0] != "a@x.com" {
+		t.Fatalf("unexpected team-a recipients: %v", got)
+	}
+	def := &models.Microservice{Name: "api", Tags: []string{"env:prod"}}
+	if got := notifier.Recipients(def); len(got) != 1 || got[0] != "default@x.com" {
+		t.Fatalf("unexpected default recipients: %v", got)
+	}
+}
@@ -1,17 +1,77 @@
 package utils
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"sync/atomic"
 )
 
+// Level es el nivel mínimo de los mensajes que se escriben.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[string]Level{
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+}
+
+var level atomic.Int32
+
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// ParseLevel convierte el nombre de un nivel (debug, info, warn o error).
+func ParseLevel(name string) (Level, error) {
+	l, ok := levelNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return LevelInfo, fmt.Errorf("nivel de log desconocido %q: debe ser debug, info, warn o error", name)
+	}
+	return l, nil
+}
+
+// SetLevel descarta los mensajes por debajo del nivel indicado.
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+func enabled(l Level) bool {
+	return Level(level.Load()) <= l
+}
+
 func InitLogger() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 }
 
+func LogDebug(msg string) {
+	if enabled(LevelDebug) {
+		log.Output(2, "[DEBUG] "+msg)
+	}
+}
+
 func LogInfo(msg string) {
-	log.Println("[INFO]", msg)
+	if enabled(LevelInfo) {
+		log.Output(2, "[INFO] "+msg)
+	}
+}
+
+func LogWarn(msg string) {
+	if enabled(LevelWarn) {
+		log.Output(2, "[WARN] "+msg)
+	}
 }
 
 func LogError(msg string) {
-	log.Println("[ERROR]", msg)
+	if enabled(LevelError) {
+		log.Output(2, "[ERROR] "+msg)
+	}
 }